@@ -1,19 +1,29 @@
 package teller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 )
 
-type TellerAccountType = string
+type TellerAccountType string
 
 const (
 	TellerAccountTypeDepository TellerAccountType = "depository"
 	TellerAccountTypeCredit     TellerAccountType = "credit"
 )
 
-type TellerAccountSubtype = string
+// IsKnown reports whether t is an account type this version of the library knows.
+// Teller may add values, which decode without error but are not known.
+func (t TellerAccountType) IsKnown() bool {
+	switch t {
+	case TellerAccountTypeDepository, TellerAccountTypeCredit:
+		return true
+	}
+	return false
+}
+
+type TellerAccountSubtype string
 
 const (
 	TellerAccountSubtypeChecking             TellerAccountSubtype = "checking"
@@ -25,59 +35,229 @@ const (
 	TellerAccountSubtypeSweep                TellerAccountSubtype = "sweep"
 )
 
-type TellerAccountStatusType = string
+// IsKnown reports whether s is an account subtype this version of the library knows.
+// Teller may add values, which decode without error but are not known.
+func (s TellerAccountSubtype) IsKnown() bool {
+	switch s {
+	case TellerAccountSubtypeChecking, TellerAccountSubtypeSavings, TellerAccountSubtypeMoneyMarket,
+		TellerAccountSubtypeCertificateOfDeposit, TellerAccountSubtypeTreasury, TellerAccountSubtypeCreditCard,
+		TellerAccountSubtypeSweep:
+		return true
+	}
+	return false
+}
+
+type TellerAccountStatusType string
 
 const (
 	TellerAccountStatusTypeOpen   TellerAccountStatusType = "open"
 	TellerAccountStatusTypeClosed TellerAccountStatusType = "closed"
 )
 
+// IsKnown reports whether s is an account status this version of the library knows.
+// Teller may add values, which decode without error but are not known.
+func (s TellerAccountStatusType) IsKnown() bool {
+	switch s {
+	case TellerAccountStatusTypeOpen, TellerAccountStatusTypeClosed:
+		return true
+	}
+	return false
+}
+
+// AccountInstitution identifies the institution holding an account
+type AccountInstitution struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Extra holds institution fields added to the API after this version of the library
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// AccountLinks holds the URLs of an account's resources
+type AccountLinks struct {
+	Self         string `json:"self"`
+	Details      string `json:"details"`
+	Balances     string `json:"balances"`
+	Transactions string `json:"transactions"`
+	// Extra holds links to account resources this version of the library does not know
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// AccountResourceLinks holds the URLs of a resource belonging to an account
+type AccountResourceLinks struct {
+	Self    string `json:"self"`
+	Account string `json:"account"`
+	// Extra holds links this version of the library does not know
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
 // TellerAccount represents a bank account
 type TellerAccount struct {
-	Currency     string            `json:"currency"`
-	EnrollmentID string            `json:"enrollment_id"`
-	ID           string            `json:"id"`
-	Name         string            `json:"name"`
-	Type         TellerAccountType `json:"type"` // "depository" or "credit"
-	Institution  struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-	} `json:"institution"`
-	LastFour string `json:"last_four"`
-	Links    struct {
-		Self         string `json:"self"`
-		Details      string `json:"details"`
-		Balances     string `json:"balances"`
-		Transactions string `json:"transactions"`
-	} `json:"links"`
-	Subtype TellerAccountSubtype    `json:"subtype"` // "checking", "savings", etc.
-	Status  TellerAccountStatusType `json:"status"`  // "open" or "closed"
+	Currency     string                  `json:"currency"`
+	EnrollmentID string                  `json:"enrollment_id"`
+	ID           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	Type         TellerAccountType       `json:"type"` // "depository" or "credit"
+	Institution  AccountInstitution      `json:"institution"`
+	LastFour     string                  `json:"last_four"`
+	Links        AccountLinks            `json:"links"`
+	Subtype      TellerAccountSubtype    `json:"subtype"` // "checking", "savings", etc.
+	Status       TellerAccountStatusType `json:"status"`  // "open" or "closed"
+	// Extra holds account fields added to the API after this version of the library;
+	// they are kept when the account is encoded again or saved to a Store
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// RoutingNumbers holds the routing numbers of an account, nil when the network is not supported
+type RoutingNumbers struct {
+	ACH  *string `json:"ach"`
+	Wire *string `json:"wire"`
+	BACS *string `json:"bacs"`
+	// Extra holds routing numbers of networks this version of the library does not know
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // TellerAccountDetails represents detailed account information
 type TellerAccountDetails struct {
-	AccountID     string `json:"account_id"`
-	AccountNumber string `json:"account_number"`
-	Links         struct {
-		Self    string `json:"self"`
-		Account string `json:"account"`
-	} `json:"links"`
-	RoutingNumbers struct {
-		ACH  *string `json:"ach"`
-		Wire *string `json:"wire"`
-		BACS *string `json:"bacs"`
-	} `json:"routing_numbers"`
+	AccountID      string               `json:"account_id"`
+	AccountNumber  string               `json:"account_number"`
+	Links          AccountResourceLinks `json:"links"`
+	RoutingNumbers RoutingNumbers       `json:"routing_numbers"`
+	// Extra holds detail fields added to the API after this version of the library
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // TellerAccountBalances represents account balance information
 type TellerAccountBalances struct {
-	AccountID string `json:"account_id"`
-	Ledger    string `json:"ledger"`
-	Available string `json:"available"`
-	Links     struct {
-		Self    string `json:"self"`
-		Account string `json:"account"`
-	} `json:"links"`
+	AccountID string               `json:"account_id"`
+	Ledger    string               `json:"ledger"`
+	Available string               `json:"available"`
+	Links     AccountResourceLinks `json:"links"`
+	// Extra holds balance fields added to the API after this version of the library
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the account, keeping unknown fields in Extra
+func (a *TellerAccount) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, a)
+}
+
+// MarshalJSON encodes the account with the unknown fields kept in Extra
+func (a TellerAccount) MarshalJSON() ([]byte, error) {
+	return marshalResource(a)
+}
+
+// Validate returns a *SchemaError if the account has unknown fields or enum values
+func (a TellerAccount) Validate() error {
+	return checkSchema("", a)
+}
+
+func (a TellerAccount) schemaProblems(path string) []string {
+	problems := unknownFieldProblems(path, a.Extra)
+	problems = append(problems, unknownValueProblem(fieldPath(path, "type"), a.Type, a.Type.IsKnown())...)
+	problems = append(problems, unknownValueProblem(fieldPath(path, "subtype"), a.Subtype, a.Subtype.IsKnown())...)
+	problems = append(problems, unknownValueProblem(fieldPath(path, "status"), a.Status, a.Status.IsKnown())...)
+	problems = append(problems, a.Institution.schemaProblems(fieldPath(path, "institution"))...)
+	problems = append(problems, a.Links.schemaProblems(fieldPath(path, "links"))...)
+	return problems
+}
+
+// UnmarshalJSON decodes the details, keeping unknown fields in Extra
+func (d *TellerAccountDetails) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, d)
+}
+
+// MarshalJSON encodes the details with the unknown fields kept in Extra
+func (d TellerAccountDetails) MarshalJSON() ([]byte, error) {
+	return marshalResource(d)
+}
+
+// Validate returns a *SchemaError if the details have unknown fields or enum values
+func (d TellerAccountDetails) Validate() error {
+	return checkSchema("", d)
+}
+
+func (d TellerAccountDetails) schemaProblems(path string) []string {
+	problems := unknownFieldProblems(path, d.Extra)
+	problems = append(problems, d.Links.schemaProblems(fieldPath(path, "links"))...)
+	problems = append(problems, d.RoutingNumbers.schemaProblems(fieldPath(path, "routing_numbers"))...)
+	return problems
+}
+
+// UnmarshalJSON decodes the balances, keeping unknown fields in Extra
+func (b *TellerAccountBalances) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, b)
+}
+
+// MarshalJSON encodes the balances with the unknown fields kept in Extra
+func (b TellerAccountBalances) MarshalJSON() ([]byte, error) {
+	return marshalResource(b)
+}
+
+// Validate returns a *SchemaError if the balances have unknown fields or enum values
+func (b TellerAccountBalances) Validate() error {
+	return checkSchema("", b)
+}
+
+func (b TellerAccountBalances) schemaProblems(path string) []string {
+	problems := unknownFieldProblems(path, b.Extra)
+	problems = append(problems, b.Links.schemaProblems(fieldPath(path, "links"))...)
+	return problems
+}
+
+// UnmarshalJSON decodes the institution, keeping unknown fields in Extra
+func (i *AccountInstitution) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, i)
+}
+
+// MarshalJSON encodes the institution with the unknown fields kept in Extra
+func (i AccountInstitution) MarshalJSON() ([]byte, error) {
+	return marshalResource(i)
+}
+
+func (i AccountInstitution) schemaProblems(path string) []string {
+	return unknownFieldProblems(path, i.Extra)
+}
+
+// UnmarshalJSON decodes the links, keeping unknown fields in Extra
+func (l *AccountLinks) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, l)
+}
+
+// MarshalJSON encodes the links with the unknown fields kept in Extra
+func (l AccountLinks) MarshalJSON() ([]byte, error) {
+	return marshalResource(l)
+}
+
+func (l AccountLinks) schemaProblems(path string) []string {
+	return unknownFieldProblems(path, l.Extra)
+}
+
+// UnmarshalJSON decodes the links, keeping unknown fields in Extra
+func (l *AccountResourceLinks) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, l)
+}
+
+// MarshalJSON encodes the links with the unknown fields kept in Extra
+func (l AccountResourceLinks) MarshalJSON() ([]byte, error) {
+	return marshalResource(l)
+}
+
+func (l AccountResourceLinks) schemaProblems(path string) []string {
+	return unknownFieldProblems(path, l.Extra)
+}
+
+// UnmarshalJSON decodes the routing numbers, keeping unknown fields in Extra
+func (r *RoutingNumbers) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, r)
+}
+
+// MarshalJSON encodes the routing numbers with the unknown fields kept in Extra
+func (r RoutingNumbers) MarshalJSON() ([]byte, error) {
+	return marshalResource(r)
+}
+
+func (r RoutingNumbers) schemaProblems(path string) []string {
+	return unknownFieldProblems(path, r.Extra)
 }
 
 // AccountModule handles account-related API calls
@@ -87,25 +267,13 @@ type AccountModule struct {
 
 // List retrieves all accounts
 func (m *AccountModule) List(options *TellerOptionsBase) ([]TellerAccount, error) {
-	req, err := http.NewRequest("GET", m.client.baseURL+"/accounts", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if options != nil && options.AccessToken != "" {
-		req.SetBasicAuth(options.AccessToken, "")
-	} else if m.client.accessToken != "" {
-		req.SetBasicAuth(m.client.accessToken, "")
-	}
-
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return m.ListContext(context.Background(), options)
+}
 
+// ListContext is List with a context, which cancels the request and any wait for the rate limiter
+func (m *AccountModule) ListContext(ctx context.Context, options *TellerOptionsBase) ([]TellerAccount, error) {
 	var result []TellerAccount
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := m.client.do(ctx, "GET", EndpointAccounts, "/accounts", m.client.token(options), &result); err != nil {
 		return nil, err
 	}
 
@@ -114,25 +282,13 @@ func (m *AccountModule) List(options *TellerOptionsBase) ([]TellerAccount, error
 
 // Get retrieves a single account by ID
 func (m *AccountModule) Get(id string, options *TellerOptionsBase) (*TellerAccount, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/accounts/%s", m.client.baseURL, id), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if options != nil && options.AccessToken != "" {
-		req.SetBasicAuth(options.AccessToken, "")
-	} else if m.client.accessToken != "" {
-		req.SetBasicAuth(m.client.accessToken, "")
-	}
-
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return m.GetContext(context.Background(), id, options)
+}
 
+// GetContext is Get with a context
+func (m *AccountModule) GetContext(ctx context.Context, id string, options *TellerOptionsBase) (*TellerAccount, error) {
 	var result TellerAccount
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := m.client.do(ctx, "GET", EndpointAccount, fmt.Sprintf("/accounts/%s", id), m.client.token(options), &result); err != nil {
 		return nil, err
 	}
 
@@ -141,69 +297,33 @@ func (m *AccountModule) Get(id string, options *TellerOptionsBase) (*TellerAccou
 
 // Remove deletes a single account by ID
 func (m *AccountModule) Remove(id string, options *TellerOptionsBase) error {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/accounts/%s", m.client.baseURL, id), nil)
-	if err != nil {
-		return err
-	}
-
-	if options != nil && options.AccessToken != "" {
-		req.SetBasicAuth(options.AccessToken, "")
-	} else if m.client.accessToken != "" {
-		req.SetBasicAuth(m.client.accessToken, "")
-	}
-
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+	return m.RemoveContext(context.Background(), id, options)
+}
 
-	return nil
+// RemoveContext is Remove with a context
+func (m *AccountModule) RemoveContext(ctx context.Context, id string, options *TellerOptionsBase) error {
+	return m.client.do(ctx, "DELETE", EndpointAccount, fmt.Sprintf("/accounts/%s", id), m.client.token(options), nil)
 }
 
 // RemoveAll deletes all accounts
 func (m *AccountModule) RemoveAll(options *TellerOptionsBase) error {
-	req, err := http.NewRequest("DELETE", m.client.baseURL+"/accounts", nil)
-	if err != nil {
-		return err
-	}
-
-	if options != nil && options.AccessToken != "" {
-		req.SetBasicAuth(options.AccessToken, "")
-	} else if m.client.accessToken != "" {
-		req.SetBasicAuth(m.client.accessToken, "")
-	}
-
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+	return m.RemoveAllContext(context.Background(), options)
+}
 
-	return nil
+// RemoveAllContext is RemoveAll with a context
+func (m *AccountModule) RemoveAllContext(ctx context.Context, options *TellerOptionsBase) error {
+	return m.client.do(ctx, "DELETE", EndpointAccounts, "/accounts", m.client.token(options), nil)
 }
 
 // Details retrieves detailed information for an account
 func (m *AccountModule) Details(id string, options *TellerOptionsBase) (*TellerAccountDetails, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/accounts/%s/details", m.client.baseURL, id), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if options != nil && options.AccessToken != "" {
-		req.SetBasicAuth(options.AccessToken, "")
-	} else if m.client.accessToken != "" {
-		req.SetBasicAuth(m.client.accessToken, "")
-	}
-
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return m.DetailsContext(context.Background(), id, options)
+}
 
+// DetailsContext is Details with a context
+func (m *AccountModule) DetailsContext(ctx context.Context, id string, options *TellerOptionsBase) (*TellerAccountDetails, error) {
 	var result TellerAccountDetails
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := m.client.do(ctx, "GET", EndpointAccountDetails, fmt.Sprintf("/accounts/%s/details", id), m.client.token(options), &result); err != nil {
 		return nil, err
 	}
 
@@ -212,25 +332,13 @@ func (m *AccountModule) Details(id string, options *TellerOptionsBase) (*TellerA
 
 // Balances retrieves balance information for an account
 func (m *AccountModule) Balances(id string, options *TellerOptionsBase) (*TellerAccountBalances, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/accounts/%s/balances", m.client.baseURL, id), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if options != nil && options.AccessToken != "" {
-		req.SetBasicAuth(options.AccessToken, "")
-	} else if m.client.accessToken != "" {
-		req.SetBasicAuth(m.client.accessToken, "")
-	}
-
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return m.BalancesContext(context.Background(), id, options)
+}
 
+// BalancesContext is Balances with a context
+func (m *AccountModule) BalancesContext(ctx context.Context, id string, options *TellerOptionsBase) (*TellerAccountBalances, error) {
 	var result TellerAccountBalances
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := m.client.do(ctx, "GET", EndpointAccountBalances, fmt.Sprintf("/accounts/%s/balances", id), m.client.token(options), &result); err != nil {
 		return nil, err
 	}
 
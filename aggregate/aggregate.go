@@ -0,0 +1,324 @@
+// Package aggregate summarizes transactions into cash-flow reports.
+//
+//	report := aggregate.CashFlow(transactions, &aggregate.Options{
+//		Accounts:         accounts,
+//		Period:           aggregate.PeriodMonth,
+//		ExcludeTransfers: true,
+//	})
+//
+// Amounts are never summed across currencies: a report holds one summary per
+// currency, taken from the transactions' accounts. Results encode to JSON with
+// amounts as decimal strings, the way the Teller API returns them.
+package aggregate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/maxint-app/teller-go"
+)
+
+const dateLayout = "2006-01-02"
+
+// Uncategorized is the category of transactions without one
+const Uncategorized = "uncategorized"
+
+type Period = string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+	PeriodYear  Period = "year"
+)
+
+// Options tunes CashFlow
+type Options struct {
+	// Period is the length of the periods of Summary.Periods, defaults to PeriodMonth
+	Period Period
+	// Accounts provide the currency of transactions, those of unlisted accounts have currency ""
+	Accounts []teller.TellerAccount
+	// ExcludeTransfers leaves out transactions IsTransfer reports as transfers
+	ExcludeTransfers bool
+	// IsTransfer defaults to the transactions teller.MatchTransfers pairs
+	// across Accounts, which are only accounts of the same currency. The
+	// default needs Accounts: transactions of unlisted accounts are never
+	// found to be transfers, so without Accounts nothing is excluded.
+	// Transfers to other parties are cash flow and kept.
+	IsTransfer func(transaction teller.TellerTransaction) bool
+	// IncludePending counts pending transactions, which are left out by default
+	IncludePending bool
+	// Categories maps transactions to the categories of Summary.Categories,
+	// defaults to Teller's category
+	Categories teller.CategoryMapper
+	// Merchants groups transactions into Summary.Counterparties, defaults to teller.NormalizeMerchant
+	Merchants *teller.MerchantNormalizer
+}
+
+// Totals sums transactions. Inflow and Outflow are both positive, Net is their difference.
+type Totals struct {
+	Inflow  teller.Amount `json:"inflow"`
+	Outflow teller.Amount `json:"outflow"`
+	Net     teller.Amount `json:"net"`
+	Count   int           `json:"count"`
+}
+
+func (t *Totals) add(amount teller.Amount) {
+	if amount >= 0 {
+		t.Inflow += amount
+	} else {
+		t.Outflow -= amount
+	}
+	t.Net += amount
+	t.Count++
+}
+
+// PeriodTotals are the totals of one period, e.g. "2026-05" for a month,
+// "2026-W19" for an ISO week. Start and End are inclusive dates.
+type PeriodTotals struct {
+	Period string `json:"period"`
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Totals
+}
+
+// GroupTotals are the totals of a category or counterparty
+type GroupTotals struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Totals
+}
+
+// Summary is the cash flow in one currency
+type Summary struct {
+	Currency string `json:"currency"`
+	Totals
+	// Periods run from the first to the last transaction without gaps, oldest first
+	Periods []PeriodTotals `json:"periods"`
+	// Categories and Counterparties are ordered by outflow, then inflow, largest first
+	Categories     []GroupTotals `json:"categories"`
+	Counterparties []GroupTotals `json:"counterparties"`
+}
+
+// Report is the cash flow of a set of transactions
+type Report struct {
+	Period Period `json:"period"`
+	// Currencies has a summary per currency, ordered by currency code
+	Currencies []Summary `json:"currencies"`
+	// Excluded counts the transfers and pending transactions left out
+	Excluded int `json:"excluded"`
+	// Invalid counts transactions whose date or amount could not be parsed
+	Invalid int `json:"invalid"`
+}
+
+// Currency returns the summary of a currency
+func (r Report) Currency(currency string) (Summary, bool) {
+	for _, summary := range r.Currencies {
+		if summary.Currency == currency {
+			return summary, true
+		}
+	}
+	return Summary{}, false
+}
+
+type summaryBuilder struct {
+	totals         Totals
+	periods        map[string]*PeriodTotals
+	first, last    time.Time
+	categories     map[string]*GroupTotals
+	counterparties map[string]*GroupTotals
+}
+
+// CashFlow sums transactions, which may span several accounts, per period,
+// category and counterparty
+func CashFlow(transactions []teller.TellerTransaction, options *Options) Report {
+	if options == nil {
+		options = &Options{}
+	}
+
+	period := options.Period
+	if period == "" {
+		period = PeriodMonth
+	}
+
+	isTransfer := options.IsTransfer
+	if isTransfer == nil && options.ExcludeTransfers {
+		internal := teller.TransferTransactionIDs(teller.MatchTransfers(options.Accounts, transactions, nil))
+		isTransfer = func(transaction teller.TellerTransaction) bool {
+			return internal[transaction.ID]
+		}
+	}
+
+	merchants := options.Merchants
+	if merchants == nil {
+		merchants = teller.NewMerchantNormalizer()
+	}
+
+	currencies := map[string]string{}
+	for _, account := range options.Accounts {
+		currencies[account.ID] = account.Currency
+	}
+
+	report := Report{Period: period}
+	builders := map[string]*summaryBuilder{}
+
+	for _, transaction := range transactions {
+		if !options.IncludePending && transaction.Status == teller.TellerTransactionStatusTypePending ||
+			options.ExcludeTransfers && isTransfer(transaction) {
+			report.Excluded++
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, transaction.Date)
+		if err != nil {
+			report.Invalid++
+			continue
+		}
+		amount, err := teller.ParseAmount(transaction.Amount)
+		if err != nil {
+			report.Invalid++
+			continue
+		}
+
+		currency := currencies[transaction.AccountID]
+		builder := builders[currency]
+		if builder == nil {
+			builder = &summaryBuilder{
+				periods:        map[string]*PeriodTotals{},
+				categories:     map[string]*GroupTotals{},
+				counterparties: map[string]*GroupTotals{},
+			}
+			builders[currency] = builder
+		}
+
+		builder.totals.add(amount)
+
+		start := periodStart(date, period)
+		if builder.first.IsZero() || start.Before(builder.first) {
+			builder.first = start
+		}
+		if start.After(builder.last) {
+			builder.last = start
+		}
+		builder.period(start, period).add(amount)
+
+		category := string(transaction.Details.Category)
+		if options.Categories != nil {
+			category = options.Categories.Category(transaction)
+		}
+		if category == "" {
+			category = Uncategorized
+		}
+		group(builder.categories, category, category).add(amount)
+
+		merchant := merchants.Transaction(transaction)
+		group(builder.counterparties, merchant.Key, merchant.Name).add(amount)
+	}
+
+	for currency, builder := range builders {
+		report.Currencies = append(report.Currencies, builder.summary(currency, period))
+	}
+	sort.Slice(report.Currencies, func(i, j int) bool {
+		return report.Currencies[i].Currency < report.Currencies[j].Currency
+	})
+
+	return report
+}
+
+func (b *summaryBuilder) period(start time.Time, period Period) *PeriodTotals {
+	label := periodLabel(start, period)
+	totals, ok := b.periods[label]
+	if !ok {
+		totals = &PeriodTotals{
+			Period: label,
+			Start:  start.Format(dateLayout),
+			End:    nextPeriod(start, period).AddDate(0, 0, -1).Format(dateLayout),
+		}
+		b.periods[label] = totals
+	}
+	return totals
+}
+
+func group(groups map[string]*GroupTotals, key, name string) *GroupTotals {
+	totals, ok := groups[key]
+	if !ok {
+		totals = &GroupTotals{Key: key, Name: name}
+		groups[key] = totals
+	}
+	return totals
+}
+
+func (b *summaryBuilder) summary(currency string, period Period) Summary {
+	summary := Summary{Currency: currency, Totals: b.totals}
+
+	// Fill empty periods so that charts get a continuous series
+	for start := b.first; !start.After(b.last); start = nextPeriod(start, period) {
+		summary.Periods = append(summary.Periods, *b.period(start, period))
+	}
+
+	summary.Categories = sortedGroups(b.categories)
+	summary.Counterparties = sortedGroups(b.counterparties)
+
+	return summary
+}
+
+func sortedGroups(groups map[string]*GroupTotals) []GroupTotals {
+	result := make([]GroupTotals, 0, len(groups))
+	for _, totals := range groups {
+		result = append(result, *totals)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Outflow != result[j].Outflow {
+			return result[i].Outflow > result[j].Outflow
+		}
+		if result[i].Inflow != result[j].Inflow {
+			return result[i].Inflow > result[j].Inflow
+		}
+		return result[i].Key < result[j].Key
+	})
+
+	return result
+}
+
+// periodStart returns the first day of the period containing date, weeks starting on Monday
+func periodStart(date time.Time, period Period) time.Time {
+	switch period {
+	case PeriodDay:
+		return date
+	case PeriodWeek:
+		return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	case PeriodYear:
+		return time.Date(date.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func nextPeriod(start time.Time, period Period) time.Time {
+	switch period {
+	case PeriodDay:
+		return start.AddDate(0, 0, 1)
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case PeriodYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+func periodLabel(start time.Time, period Period) string {
+	switch period {
+	case PeriodDay:
+		return start.Format(dateLayout)
+	case PeriodWeek:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case PeriodYear:
+		return start.Format("2006")
+	default:
+		return start.Format("2006-01")
+	}
+}
@@ -0,0 +1,203 @@
+package aggregate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/maxint-app/teller-go"
+)
+
+var testAccounts = []teller.TellerAccount{
+	{ID: "chk", Type: teller.TellerAccountTypeDepository, Currency: "USD"},
+	{ID: "sav", Type: teller.TellerAccountTypeDepository, Currency: "USD"},
+	{ID: "eur", Type: teller.TellerAccountTypeDepository, Currency: "EUR"},
+}
+
+// testTransaction returns a posted transaction of accountID
+func testTransaction(id, accountID, date, description, amount string, category teller.TransactionCategory) teller.TellerTransaction {
+	transaction := teller.TellerTransaction{
+		ID:          id,
+		AccountID:   accountID,
+		Amount:      amount,
+		Date:        date,
+		Description: description,
+		Status:      teller.TellerTransactionStatusTypePosted,
+	}
+	transaction.Details.Category = category
+	return transaction
+}
+
+func TestCashFlow(t *testing.T) {
+	transactions := []teller.TellerTransaction{
+		testTransaction("pay", "chk", "2026-01-15", "ACME PAYROLL", "3000.00", teller.TransactionCategoryIncome),
+		testTransaction("rent", "chk", "2026-01-01", "OAK APARTMENTS", "-1500.00", teller.TransactionCategoryHome),
+		testTransaction("food", "chk", "2026-03-04", "WHOLE FOODS", "-80.25", teller.TransactionCategoryGroceries),
+		testTransaction("other", "chk", "2026-03-05", "CASH", "-10.00", ""),
+		testTransaction("bad", "chk", "not a date", "CASH", "-10.00", ""),
+	}
+
+	report := CashFlow(transactions, &Options{Accounts: testAccounts})
+
+	if report.Period != PeriodMonth || report.Invalid != 1 || report.Excluded != 0 {
+		t.Fatalf("CashFlow() period %q, invalid %d, excluded %d", report.Period, report.Invalid, report.Excluded)
+	}
+
+	usd, ok := report.Currency("USD")
+	if !ok || len(report.Currencies) != 1 {
+		t.Fatalf("CashFlow() currencies = %+v", report.Currencies)
+	}
+	if usd.Inflow != 300000 || usd.Outflow != 159025 || usd.Net != 140975 || usd.Count != 4 {
+		t.Errorf("totals = %+v", usd.Totals)
+	}
+
+	var periods []string
+	for _, period := range usd.Periods {
+		periods = append(periods, period.Period+":"+period.Net.String())
+	}
+	if got, want := strings.Join(periods, " "), "2026-01:1500.00 2026-02:0.00 2026-03:-90.25"; got != want {
+		t.Errorf("periods = %s, want %s", got, want)
+	}
+	if usd.Periods[1].Start != "2026-02-01" || usd.Periods[1].End != "2026-02-28" {
+		t.Errorf("February runs %s to %s", usd.Periods[1].Start, usd.Periods[1].End)
+	}
+
+	var categories []string
+	for _, category := range usd.Categories {
+		categories = append(categories, category.Key)
+	}
+	if got, want := strings.Join(categories, " "), "home groceries uncategorized income"; got != want {
+		t.Errorf("categories = %s, want %s", got, want)
+	}
+}
+
+func TestCashFlowPeriods(t *testing.T) {
+	transactions := []teller.TellerTransaction{
+		testTransaction("a", "chk", "2026-05-10", "A", "-1.00", ""),
+		testTransaction("b", "chk", "2026-05-12", "B", "-1.00", ""),
+	}
+
+	tests := []struct {
+		period Period
+		want   string
+	}{
+		{PeriodDay, "2026-05-10 2026-05-11 2026-05-12"},
+		{PeriodWeek, "2026-W19 2026-W20"},
+		{PeriodMonth, "2026-05"},
+		{PeriodYear, "2026"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			report := CashFlow(transactions, &Options{Accounts: testAccounts, Period: tt.period})
+
+			var periods []string
+			for _, period := range report.Currencies[0].Periods {
+				periods = append(periods, period.Period)
+			}
+			if got := strings.Join(periods, " "); got != tt.want {
+				t.Errorf("periods = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCashFlowCurrencies(t *testing.T) {
+	transactions := []teller.TellerTransaction{
+		testTransaction("usd", "chk", "2026-05-01", "A", "-10.00", ""),
+		testTransaction("eur", "eur", "2026-05-01", "B", "-20.00", ""),
+		testTransaction("unknown", "other", "2026-05-01", "C", "-30.00", ""),
+	}
+
+	report := CashFlow(transactions, &Options{Accounts: testAccounts})
+
+	var got []string
+	for _, summary := range report.Currencies {
+		got = append(got, summary.Currency+":"+summary.Net.String())
+	}
+	if want := ":-30.00 EUR:-20.00 USD:-10.00"; strings.Join(got, " ") != want {
+		t.Errorf("currencies = %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestCashFlowExclusions(t *testing.T) {
+	transactions := []teller.TellerTransaction{
+		testTransaction("out", "chk", "2026-05-01", "TRANSFER TO SAV", "-500.00", ""),
+		testTransaction("in", "sav", "2026-05-01", "TRANSFER FROM CHK", "500.00", ""),
+		testTransaction("eur_in", "eur", "2026-05-01", "TRANSFER FROM CHK", "500.00", ""),
+		testTransaction("rent", "chk", "2026-05-02", "OAK APARTMENTS", "-1500.00", ""),
+		testTransaction("pay", "sav", "2026-05-02", "ACME CORP", "1500.00", ""),
+	}
+	pending := testTransaction("pending", "chk", "2026-05-03", "COFFEE", "-4.50", "")
+	pending.Status = teller.TellerTransactionStatusTypePending
+	transactions = append(transactions, pending)
+
+	tests := []struct {
+		name     string
+		options  *Options
+		excluded int
+		count    int
+	}{
+		{
+			name:     "pending only by default",
+			options:  &Options{Accounts: testAccounts},
+			excluded: 1,
+			count:    5,
+		},
+		{
+			name:     "include pending",
+			options:  &Options{Accounts: testAccounts, IncludePending: true},
+			excluded: 0,
+			count:    6,
+		},
+		{
+			// The cross-currency leg and the paycheck and rent pair are kept
+			name:     "transfers",
+			options:  &Options{Accounts: testAccounts, ExcludeTransfers: true},
+			excluded: 3,
+			count:    3,
+		},
+		{
+			name:     "transfers without accounts",
+			options:  &Options{ExcludeTransfers: true},
+			excluded: 1,
+			count:    5,
+		},
+		{
+			name: "custom transfers",
+			options: &Options{Accounts: testAccounts, ExcludeTransfers: true, IsTransfer: func(transaction teller.TellerTransaction) bool {
+				return transaction.ID == "rent"
+			}},
+			excluded: 2,
+			count:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CashFlow(transactions, tt.options)
+
+			count := 0
+			for _, summary := range report.Currencies {
+				count += summary.Count
+			}
+			if report.Excluded != tt.excluded || count != tt.count {
+				t.Errorf("CashFlow() excluded %d, counted %d, want %d and %d", report.Excluded, count, tt.excluded, tt.count)
+			}
+		})
+	}
+}
+
+func TestCashFlowJSON(t *testing.T) {
+	report := CashFlow([]teller.TellerTransaction{
+		testTransaction("a", "chk", "2026-05-01", "A", "-12.30", ""),
+	}, &Options{Accounts: testAccounts})
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"outflow":"12.30"`) || !strings.Contains(string(data), `"net":"-12.30"`) {
+		t.Errorf("json.Marshal() = %s, want amounts as decimal strings", data)
+	}
+}
@@ -0,0 +1,100 @@
+package teller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Amount is a monetary value in hundredths of the currency unit, as used for
+// the decimal strings returned by the API (amounts, balances)
+type Amount int64
+
+// ParseAmount parses a decimal string such as "-12.50" into an Amount
+func ParseAmount(s string) (Amount, error) {
+	value := strings.TrimSpace(s)
+
+	negative := false
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" || len(fraction) > 2 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+
+	var units int64
+	if whole != "" {
+		n, err := strconv.ParseUint(whole, 10, 63)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		units = int64(n)
+	}
+
+	cents, err := strconv.ParseUint(fraction, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	result := Amount(units*100 + int64(cents))
+	if negative {
+		result = -result
+	}
+
+	return result, nil
+}
+
+// String formats the amount the way the API does, e.g. "-12.50"
+func (a Amount) String() string {
+	sign := ""
+	// Negate as unsigned so the smallest Amount does not overflow
+	v := uint64(a)
+	if a < 0 {
+		sign = "-"
+		v = -v
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// Abs returns the absolute value of the amount
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// MarshalText encodes the amount the way the API does, e.g. "-12.50"
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses a decimal string such as "-12.50"
+func (a *Amount) UnmarshalText(text []byte) error {
+	amount, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = amount
+	return nil
+}
+
+// UnmarshalJSON parses a decimal string or a JSON number. Like the standard
+// library, it leaves the amount unchanged for a JSON null
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if s, err := strconv.Unquote(string(data)); err == nil {
+		data = []byte(s)
+	}
+	return a.UnmarshalText(data)
+}
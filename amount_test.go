@@ -0,0 +1,62 @@
+package teller
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestAmountString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-1250, "-12.50"},
+		{123456, "1234.56"},
+		{math.MaxInt64, "92233720368547758.07"},
+		{math.MinInt64, "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", int64(tt.amount), got, tt.want)
+		}
+	}
+}
+
+func TestAmountUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    Amount
+		wantErr bool
+	}{
+		{`"-12.50"`, -1250, false},
+		{`12.5`, 1250, false},
+		{`"7"`, 700, false},
+		{`null`, 42, false},
+		{`"1.234"`, 0, true},
+		{`"abc"`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			got := Amount(42)
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Unmarshal(%s) = %d, want %d", tt.data, got, tt.want)
+			}
+		})
+	}
+
+	var fields struct {
+		Balance *Amount `json:"balance"`
+	}
+	if err := json.Unmarshal([]byte(`{"balance": null}`), &fields); err != nil || fields.Balance != nil {
+		t.Errorf("Unmarshal null pointer = %v, %v, want nil, nil", fields.Balance, err)
+	}
+}
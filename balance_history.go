@@ -0,0 +1,167 @@
+package teller
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+type BalancePointSource = string
+
+const (
+	// BalancePointSourceSnapshot marks a balance observed by a recorded snapshot
+	BalancePointSourceSnapshot BalancePointSource = "snapshot"
+	// BalancePointSourceRunningBalance marks a balance reported by a transaction's running balance
+	BalancePointSourceRunningBalance BalancePointSource = "running_balance"
+	// BalancePointSourceReconstructed marks a balance derived from transaction amounts
+	BalancePointSourceReconstructed BalancePointSource = "reconstructed"
+)
+
+// BalancePoint is the end-of-day ledger balance of an account
+type BalancePoint struct {
+	Date   string             `json:"date"` // YYYY-MM-DD
+	Ledger string             `json:"ledger"`
+	Source BalancePointSource `json:"source"`
+}
+
+// BalanceHistory records balance snapshots in a Store and builds daily
+// balance time series from them and the stored transactions
+type BalanceHistory struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewBalanceHistory creates a balance history backed by a store
+func NewBalanceHistory(store Store) *BalanceHistory {
+	return &BalanceHistory{store: store, now: time.Now}
+}
+
+// Record stores the current balances of an account as a snapshot
+func (h *BalanceHistory) Record(ctx context.Context, balances TellerAccountBalances) error {
+	return h.store.PutBalanceSnapshot(ctx, NewBalanceSnapshot(balances, h.now().UTC()))
+}
+
+// Daily returns end-of-day ledger balances for an account from `from` up to
+// the most recent snapshot, oldest first. The account, its latest snapshot
+// and its transactions must have been stored beforehand.
+func (h *BalanceHistory) Daily(ctx context.Context, accountID string, from time.Time) ([]BalancePoint, error) {
+	account, err := h.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := h.store.ListBalanceSnapshots(ctx, accountID, from, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return []BalancePoint{}, nil
+	}
+
+	startDate := from.UTC().Format(dateLayout)
+	transactions, err := h.store.ListTransactions(ctx, StoreTransactionQuery{
+		AccountID: accountID,
+		StartDate: &startDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	latest := snapshots[len(snapshots)-1]
+	points, err := ReconstructDailyBalances(*account, latest.Ledger, latest.TakenAt, transactions, from)
+	if err != nil {
+		return nil, err
+	}
+
+	// Observed balances win over derived ones. Snapshots are sorted oldest
+	// first, so the last snapshot of each day is the one kept.
+	observed := map[string]string{}
+	for _, snapshot := range snapshots {
+		observed[snapshot.TakenAt.UTC().Format(dateLayout)] = snapshot.Ledger
+	}
+	for i, point := range points {
+		if ledger, ok := observed[point.Date]; ok {
+			points[i] = BalancePoint{Date: point.Date, Ledger: ledger, Source: BalancePointSourceSnapshot}
+		}
+	}
+
+	return points, nil
+}
+
+// ReconstructDailyBalances walks backwards from the ledger balance known at
+// asOf and derives the end-of-day balance for every day down to from,
+// returning the series oldest first.
+//
+// Posted transactions are undone day by day. When a transaction carries a
+// running balance, the series is re-anchored to it for that day. Pending
+// transactions are ignored since they are not part of the ledger balance.
+// For credit accounts the ledger is the amount owed, so transaction amounts
+// move it in the opposite direction.
+func ReconstructDailyBalances(account TellerAccount, ledger string, asOf time.Time, transactions []TellerTransaction, from time.Time) ([]BalancePoint, error) {
+	balance, err := ParseAmount(ledger)
+	if err != nil {
+		return nil, err
+	}
+
+	sign := Amount(1)
+	if account.Type == TellerAccountTypeCredit {
+		sign = -1
+	}
+
+	// Transactions arrive newest first; keep that order within each day so
+	// the first running balance seen for a day is its end-of-day value.
+	ordered := make([]TellerTransaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Date > ordered[j].Date })
+
+	byDate := map[string][]TellerTransaction{}
+	for _, transaction := range ordered {
+		if transaction.AccountID != account.ID || transaction.Status == TellerTransactionStatusTypePending {
+			continue
+		}
+		byDate[transaction.Date] = append(byDate[transaction.Date], transaction)
+	}
+
+	end := asOf.UTC().Truncate(24 * time.Hour)
+	start := from.UTC().Truncate(24 * time.Hour)
+
+	points := []BalancePoint{}
+	for day := end; !day.Before(start); day = day.AddDate(0, 0, -1) {
+		date := day.Format(dateLayout)
+		source := BalancePointSourceReconstructed
+		if day.Equal(end) {
+			source = BalancePointSourceSnapshot
+		}
+
+		var net Amount
+		for _, transaction := range byDate[date] {
+			if transaction.RunningBalance != nil && source != BalancePointSourceRunningBalance {
+				running, err := ParseAmount(*transaction.RunningBalance)
+				if err != nil {
+					return nil, err
+				}
+				// The running balance excludes the later transactions of
+				// the same day that have already been summed into net.
+				balance = running + sign*net
+				source = BalancePointSourceRunningBalance
+			}
+
+			amount, err := ParseAmount(transaction.Amount)
+			if err != nil {
+				return nil, err
+			}
+			net += amount
+		}
+
+		points = append(points, BalancePoint{Date: date, Ledger: balance.String(), Source: source})
+		balance -= sign * net
+	}
+
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+
+	return points, nil
+}
@@ -0,0 +1,382 @@
+package teller
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCacheTTLs caches the resources that rarely change. Endpoints
+// without a TTL are never cached.
+var DefaultCacheTTLs = map[Endpoint]time.Duration{
+	EndpointInstitutions:   24 * time.Hour,
+	EndpointAccountDetails: 24 * time.Hour,
+	EndpointIdentity:       time.Hour,
+}
+
+// CachedResponse is a successful GET response held by a Cache
+type CachedResponse struct {
+	Endpoint         Endpoint
+	AccountID        string
+	TokenFingerprint string
+	Header           http.Header
+	Body             []byte
+	ETag             string
+	Expires          time.Time
+}
+
+// Cache stores responses by key. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	// DeleteFunc removes the responses matching fn and returns how many were removed
+	DeleteFunc(fn func(key string, response *CachedResponse) bool) int
+}
+
+type lruEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// LRUCache is an in-memory Cache evicting the least recently used response
+// once it holds more than its capacity
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+// NewLRUCache creates an in-memory cache holding up to capacity responses
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+// Get returns the response stored under key
+func (c *LRUCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry).response, true
+}
+
+// Set stores a response under key
+func (c *LRUCache) Set(key string, response *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry).response = response
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, response: response})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// DeleteFunc removes the responses matching fn
+func (c *LRUCache) DeleteFunc(fn func(key string, response *CachedResponse) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, element := range c.entries {
+		if fn(key, element.Value.(*lruEntry).response) {
+			c.order.Remove(element)
+			delete(c.entries, key)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// CacheStats reports how effective a ResponseCache is
+type CacheStats struct {
+	Hits          int64 // served from the cache, including revalidated responses
+	Misses        int64 // fetched from the API
+	Revalidations int64 // stale responses confirmed unchanged with If-None-Match
+	Invalidations int64 // responses removed by invalidation
+}
+
+// ResponseCache caches GET responses per access token and path with a TTL per endpoint.
+// Stale responses that had an ETag are revalidated with If-None-Match.
+type ResponseCache struct {
+	cache Cache
+	ttls  map[Endpoint]time.Duration
+	now   func() time.Time
+
+	mu          sync.Mutex
+	enrollments map[string]map[string]bool // enrollment ID -> token fingerprints
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	revalidations atomic.Int64
+	invalidations atomic.Int64
+}
+
+// NewResponseCache creates a response cache. A nil ttls uses DefaultCacheTTLs.
+func NewResponseCache(cache Cache, ttls map[Endpoint]time.Duration) *ResponseCache {
+	if ttls == nil {
+		ttls = DefaultCacheTTLs
+	}
+
+	return &ResponseCache{
+		cache:       cache,
+		ttls:        ttls,
+		now:         time.Now,
+		enrollments: map[string]map[string]bool{},
+	}
+}
+
+// WithCache serves cacheable responses from cache, see ResponseCache.Middleware
+func WithCache(cache *ResponseCache) ClientOption {
+	return WithMiddleware(cache.Middleware())
+}
+
+// Stats returns the counters accumulated so far
+func (rc *ResponseCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:          rc.hits.Load(),
+		Misses:        rc.misses.Load(),
+		Revalidations: rc.revalidations.Load(),
+		Invalidations: rc.invalidations.Load(),
+	}
+}
+
+// Middleware returns the middleware serving and storing cached responses.
+// A DELETE through the middleware invalidates everything cached for its access token.
+func (rc *ResponseCache) Middleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			info, _ := RequestInfoFromContext(req.Context())
+
+			if req.Method == http.MethodDelete {
+				rc.InvalidateToken(info.TokenFingerprint)
+				return next.Do(req)
+			}
+
+			if req.Method != http.MethodGet {
+				return next.Do(req)
+			}
+
+			ttl := rc.ttls[info.Endpoint]
+			if ttl <= 0 {
+				if info.Endpoint != EndpointAccounts {
+					return next.Do(req)
+				}
+				return rc.learnFromAccounts(next, req, info.TokenFingerprint)
+			}
+
+			key := info.TokenFingerprint + " " + req.URL.RequestURI()
+			cached, ok := rc.cache.Get(key)
+			if ok && rc.now().Before(cached.Expires) {
+				rc.hits.Add(1)
+				return cachedResponse(req, cached), nil
+			}
+
+			if ok && cached.ETag != "" {
+				req = req.Clone(req.Context())
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+
+			resp, err := next.Do(req)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok && resp.StatusCode == http.StatusNotModified {
+				resp.Body.Close()
+				refreshed := *cached
+				refreshed.Expires = rc.now().Add(ttl)
+				rc.cache.Set(key, &refreshed)
+				rc.hits.Add(1)
+				rc.revalidations.Add(1)
+				return cachedResponse(req, &refreshed), nil
+			}
+
+			rc.misses.Add(1)
+			if resp.StatusCode != http.StatusOK {
+				return resp, nil
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			rc.cache.Set(key, &CachedResponse{
+				Endpoint:         info.Endpoint,
+				AccountID:        info.AccountID,
+				TokenFingerprint: info.TokenFingerprint,
+				Header:           resp.Header.Clone(),
+				Body:             body,
+				ETag:             resp.Header.Get("ETag"),
+				Expires:          rc.now().Add(ttl),
+			})
+
+			switch info.Endpoint {
+			case EndpointAccounts:
+				rc.learnEnrollments(info.TokenFingerprint, accountEnrollments(body))
+			case EndpointIdentity:
+				rc.learnEnrollments(info.TokenFingerprint, identityEnrollments(body))
+			}
+
+			return resp, nil
+		})
+	}
+}
+
+// learnFromAccounts passes an uncached account list through, remembering its enrollments
+func (rc *ResponseCache) learnFromAccounts(next Doer, req *http.Request, fingerprint string) (*http.Response, error) {
+	resp, err := next.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rc.learnEnrollments(fingerprint, accountEnrollments(body))
+	return resp, nil
+}
+
+// learnEnrollments remembers which token an enrollment's data was fetched
+// with, so that enrollment.disconnected webhooks can invalidate it
+func (rc *ResponseCache) learnEnrollments(fingerprint string, enrollmentIDs []string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for _, enrollmentID := range enrollmentIDs {
+		if rc.enrollments[enrollmentID] == nil {
+			rc.enrollments[enrollmentID] = map[string]bool{}
+		}
+		rc.enrollments[enrollmentID][fingerprint] = true
+	}
+}
+
+func accountEnrollments(body []byte) []string {
+	var accounts []struct {
+		EnrollmentID string `json:"enrollment_id"`
+	}
+	json.Unmarshal(body, &accounts)
+
+	var result []string
+	for _, account := range accounts {
+		result = append(result, account.EnrollmentID)
+	}
+	return result
+}
+
+func identityEnrollments(body []byte) []string {
+	var identities []struct {
+		Account struct {
+			EnrollmentID string `json:"enrollment_id"`
+		} `json:"account"`
+	}
+	json.Unmarshal(body, &identities)
+
+	var result []string
+	for _, identity := range identities {
+		result = append(result, identity.Account.EnrollmentID)
+	}
+	return result
+}
+
+// InvalidateToken removes everything cached for an access token fingerprint
+func (rc *ResponseCache) InvalidateToken(fingerprint string) int {
+	if fingerprint == "" {
+		return 0
+	}
+
+	return rc.invalidate(func(key string, response *CachedResponse) bool {
+		return response.TokenFingerprint == fingerprint
+	})
+}
+
+// InvalidateAccount removes everything cached for an account
+func (rc *ResponseCache) InvalidateAccount(accountID string) int {
+	return rc.invalidate(func(key string, response *CachedResponse) bool {
+		return response.AccountID == accountID
+	})
+}
+
+// InvalidateWebhook removes the responses a webhook event makes stale:
+// transactions.processed invalidates the accounts of the transactions,
+// account.number_verification.processed invalidates the account, and
+// enrollment.disconnected invalidates every token the enrollment's accounts
+// or identity were fetched with.
+func (rc *ResponseCache) InvalidateWebhook(event *WebhookEvent) int {
+	switch event.Type {
+	case WebhookEventTypeTransactionsProcessed:
+		accounts := map[string]bool{}
+		for _, transaction := range event.Payload.Transactions {
+			accounts[transaction.AccountID] = true
+		}
+		return rc.invalidate(func(key string, response *CachedResponse) bool {
+			return accounts[response.AccountID]
+		})
+
+	case WebhookEventTypeAccountNumberVerificationProcessed:
+		return rc.InvalidateAccount(event.Payload.AccountID)
+
+	case WebhookEventTypeEnrollmentDisconnected:
+		rc.mu.Lock()
+		fingerprints := rc.enrollments[event.Payload.EnrollmentID]
+		delete(rc.enrollments, event.Payload.EnrollmentID)
+		rc.mu.Unlock()
+
+		return rc.invalidate(func(key string, response *CachedResponse) bool {
+			return fingerprints[response.TokenFingerprint]
+		})
+	}
+
+	return 0
+}
+
+func (rc *ResponseCache) invalidate(fn func(key string, response *CachedResponse) bool) int {
+	removed := rc.cache.DeleteFunc(fn)
+	rc.invalidations.Add(int64(removed))
+	return removed
+}
+
+func cachedResponse(req *http.Request, cached *CachedResponse) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cached.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,143 @@
+package teller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEviction(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", &CachedResponse{Body: []byte("a")})
+	cache.Set("b", &CachedResponse{Body: []byte("b")})
+	cache.Get("a")
+	cache.Set("c", &CachedResponse{Body: []byte("c")})
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.Get(key); ok != want {
+			t.Errorf("Get(%q) found = %v, want %v", key, ok, want)
+		}
+	}
+
+	removed := cache.DeleteFunc(func(key string, response *CachedResponse) bool { return key == "a" })
+	if _, ok := cache.Get("a"); removed != 1 || ok {
+		t.Errorf("DeleteFunc removed %d, Get(a) found = %v, want 1, false", removed, ok)
+	}
+}
+
+// fakeAPI answers GET requests with a body counting the calls, honouring If-None-Match
+type fakeAPI struct {
+	calls int
+	etag  string
+}
+
+func (f *fakeAPI) Do(req *http.Request) (*http.Response, error) {
+	f.calls++
+
+	header := http.Header{}
+	if f.etag != "" {
+		header.Set("ETag", f.etag)
+		if req.Header.Get("If-None-Match") == f.etag {
+			return &http.Response{StatusCode: http.StatusNotModified, Header: header, Body: http.NoBody}, nil
+		}
+	}
+
+	body := fmt.Sprintf(`[{"enrollment_id":"enr_1","call":%d}]`, f.calls)
+	return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestResponseCacheMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoint  Endpoint
+		etag      string
+		advance   time.Duration
+		wantCalls int
+		wantStats CacheStats
+	}{
+		{"fresh hit", EndpointInstitutions, "", time.Minute, 1, CacheStats{Hits: 1, Misses: 1}},
+		{"expired", EndpointInstitutions, "", 25 * time.Hour, 2, CacheStats{Misses: 2}},
+		{"revalidated", EndpointInstitutions, `"v1"`, 25 * time.Hour, 2, CacheStats{Hits: 1, Misses: 1, Revalidations: 1}},
+		{"not cached", EndpointTransactions, "", time.Minute, 2, CacheStats{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+			rc := NewResponseCache(NewLRUCache(16), nil)
+			rc.now = func() time.Time { return now }
+
+			api := &fakeAPI{etag: tt.etag}
+			doer := rc.Middleware()(api)
+
+			var bodies []string
+			for range 2 {
+				req := newCacheTestRequest(t, http.MethodGet, tt.endpoint, "tok")
+				resp, err := doer.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				bodies = append(bodies, string(body))
+				now = now.Add(tt.advance)
+			}
+
+			if api.calls != tt.wantCalls {
+				t.Errorf("API calls = %d, want %d", api.calls, tt.wantCalls)
+			}
+			if stats := rc.Stats(); stats != tt.wantStats {
+				t.Errorf("Stats() = %+v, want %+v", stats, tt.wantStats)
+			}
+			if tt.wantStats.Hits > 0 && bodies[0] != bodies[1] {
+				t.Errorf("cached body = %s, want %s", bodies[1], bodies[0])
+			}
+		})
+	}
+}
+
+func TestResponseCacheInvalidation(t *testing.T) {
+	rc := NewResponseCache(NewLRUCache(16), nil)
+	api := &fakeAPI{}
+	doer := rc.Middleware()(api)
+
+	fetch := func(method string, endpoint Endpoint, token string) {
+		t.Helper()
+		if _, err := doer.Do(newCacheTestRequest(t, method, endpoint, token)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The account list teaches the cache which token enrollment enr_1 uses
+	fetch(http.MethodGet, EndpointAccounts, "tok")
+	fetch(http.MethodGet, EndpointIdentity, "tok")
+	fetch(http.MethodGet, EndpointIdentity, "other")
+
+	removed := rc.InvalidateWebhook(&WebhookEvent{
+		Type:    WebhookEventTypeEnrollmentDisconnected,
+		Payload: WebhookPayload{EnrollmentID: "enr_1"},
+	})
+	if removed != 1 {
+		t.Errorf("InvalidateWebhook removed %d responses, want 1", removed)
+	}
+
+	fetch(http.MethodDelete, EndpointAccounts, "other")
+	if stats := rc.Stats(); stats.Invalidations != 2 {
+		t.Errorf("Invalidations = %d, want 2", stats.Invalidations)
+	}
+}
+
+func newCacheTestRequest(t *testing.T, method string, endpoint Endpoint, token string) *http.Request {
+	t.Helper()
+
+	info := RequestInfo{Method: method, Endpoint: endpoint, TokenFingerprint: TokenFingerprint(token)}
+	ctx := contextWithRequestInfo(context.Background(), info)
+	req, err := http.NewRequestWithContext(ctx, method, "https://api.teller.io"+endpoint, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
@@ -0,0 +1,223 @@
+package teller
+
+import (
+	"slices"
+	"sync"
+)
+
+// TransactionCategory is the category Teller assigns to a transaction
+type TransactionCategory string
+
+const (
+	TransactionCategoryAccommodation  TransactionCategory = "accommodation"
+	TransactionCategoryAdvertising    TransactionCategory = "advertising"
+	TransactionCategoryBar            TransactionCategory = "bar"
+	TransactionCategoryCharity        TransactionCategory = "charity"
+	TransactionCategoryClothing       TransactionCategory = "clothing"
+	TransactionCategoryDining         TransactionCategory = "dining"
+	TransactionCategoryEducation      TransactionCategory = "education"
+	TransactionCategoryElectronics    TransactionCategory = "electronics"
+	TransactionCategoryEntertainment  TransactionCategory = "entertainment"
+	TransactionCategoryFuel           TransactionCategory = "fuel"
+	TransactionCategoryGeneral        TransactionCategory = "general"
+	TransactionCategoryGroceries      TransactionCategory = "groceries"
+	TransactionCategoryHealth         TransactionCategory = "health"
+	TransactionCategoryHome           TransactionCategory = "home"
+	TransactionCategoryIncome         TransactionCategory = "income"
+	TransactionCategoryInsurance      TransactionCategory = "insurance"
+	TransactionCategoryInvestment     TransactionCategory = "investment"
+	TransactionCategoryLoan           TransactionCategory = "loan"
+	TransactionCategoryOffice         TransactionCategory = "office"
+	TransactionCategoryPhone          TransactionCategory = "phone"
+	TransactionCategoryService        TransactionCategory = "service"
+	TransactionCategoryShopping       TransactionCategory = "shopping"
+	TransactionCategorySoftware       TransactionCategory = "software"
+	TransactionCategorySport          TransactionCategory = "sport"
+	TransactionCategoryTax            TransactionCategory = "tax"
+	TransactionCategoryTransport      TransactionCategory = "transport"
+	TransactionCategoryTransportation TransactionCategory = "transportation"
+	TransactionCategoryUtilities      TransactionCategory = "utilities"
+)
+
+// CategoryGroup is a parent category grouping related transaction categories
+type CategoryGroup string
+
+const (
+	CategoryGroupFoodAndDrink  CategoryGroup = "food_and_drink"
+	CategoryGroupShopping      CategoryGroup = "shopping"
+	CategoryGroupTransport     CategoryGroup = "transport"
+	CategoryGroupTravel        CategoryGroup = "travel"
+	CategoryGroupHome          CategoryGroup = "home"
+	CategoryGroupBills         CategoryGroup = "bills"
+	CategoryGroupHealth        CategoryGroup = "health"
+	CategoryGroupEntertainment CategoryGroup = "entertainment"
+	CategoryGroupEducation     CategoryGroup = "education"
+	CategoryGroupBusiness      CategoryGroup = "business"
+	CategoryGroupFinancial     CategoryGroup = "financial"
+	CategoryGroupGiving        CategoryGroup = "giving"
+	CategoryGroupIncome        CategoryGroup = "income"
+	CategoryGroupOther         CategoryGroup = "other"
+)
+
+var categoryGroups = map[TransactionCategory]CategoryGroup{
+	TransactionCategoryBar:            CategoryGroupFoodAndDrink,
+	TransactionCategoryDining:         CategoryGroupFoodAndDrink,
+	TransactionCategoryGroceries:      CategoryGroupFoodAndDrink,
+	TransactionCategoryClothing:       CategoryGroupShopping,
+	TransactionCategoryElectronics:    CategoryGroupShopping,
+	TransactionCategoryShopping:       CategoryGroupShopping,
+	TransactionCategoryFuel:           CategoryGroupTransport,
+	TransactionCategoryTransport:      CategoryGroupTransport,
+	TransactionCategoryTransportation: CategoryGroupTransport,
+	TransactionCategoryAccommodation:  CategoryGroupTravel,
+	TransactionCategoryHome:           CategoryGroupHome,
+	TransactionCategoryPhone:          CategoryGroupBills,
+	TransactionCategorySoftware:       CategoryGroupBills,
+	TransactionCategoryUtilities:      CategoryGroupBills,
+	TransactionCategoryHealth:         CategoryGroupHealth,
+	TransactionCategorySport:          CategoryGroupHealth,
+	TransactionCategoryEntertainment:  CategoryGroupEntertainment,
+	TransactionCategoryEducation:      CategoryGroupEducation,
+	TransactionCategoryAdvertising:    CategoryGroupBusiness,
+	TransactionCategoryOffice:         CategoryGroupBusiness,
+	TransactionCategoryService:        CategoryGroupBusiness,
+	TransactionCategoryInsurance:      CategoryGroupFinancial,
+	TransactionCategoryInvestment:     CategoryGroupFinancial,
+	TransactionCategoryLoan:           CategoryGroupFinancial,
+	TransactionCategoryTax:            CategoryGroupFinancial,
+	TransactionCategoryCharity:        CategoryGroupGiving,
+	TransactionCategoryIncome:         CategoryGroupIncome,
+	TransactionCategoryGeneral:        CategoryGroupOther,
+}
+
+// IsKnown reports whether c is a category this version of the library knows.
+// Teller may add values, which decode without error but are not known.
+func (c TransactionCategory) IsKnown() bool {
+	_, ok := categoryGroups[c]
+	return ok
+}
+
+// Group returns the parent category of c, CategoryGroupOther for unknown categories
+func (c TransactionCategory) Group() CategoryGroup {
+	if group, ok := categoryGroups[c]; ok {
+		return group
+	}
+	return CategoryGroupOther
+}
+
+// Categories returns the known categories belonging to the group
+func (g CategoryGroup) Categories() []TransactionCategory {
+	var categories []TransactionCategory
+	for category, group := range categoryGroups {
+		if group == g {
+			categories = append(categories, category)
+		}
+	}
+	slices.Sort(categories)
+	return categories
+}
+
+// CategoryMapper translates a transaction into an application's own category.
+// An empty result means the mapper has no opinion.
+type CategoryMapper interface {
+	Category(transaction TellerTransaction) string
+}
+
+// CategoryMapperFunc adapts a function to the CategoryMapper interface
+type CategoryMapperFunc func(transaction TellerTransaction) string
+
+// Category calls f(transaction)
+func (f CategoryMapperFunc) Category(transaction TellerTransaction) string {
+	return f(transaction)
+}
+
+// ChainCategoryMappers returns a mapper asking each mapper in turn, using the first non-empty category
+func ChainCategoryMappers(mappers ...CategoryMapper) CategoryMapper {
+	return CategoryMapperFunc(func(transaction TellerTransaction) string {
+		for _, mapper := range mappers {
+			if category := mapper.Category(transaction); category != "" {
+				return category
+			}
+		}
+		return ""
+	})
+}
+
+// CategoryRemapper translates Teller categories into another taxonomy.
+// A transaction's category is looked up, most specific first, in the
+// merchant overrides, the category mappings and the group mappings, falling
+// back to the fallback category. It is safe for concurrent use.
+type CategoryRemapper struct {
+	mu         sync.RWMutex
+	merchants  map[string]string
+	categories map[TransactionCategory]string
+	groups     map[CategoryGroup]string
+	fallback   string
+}
+
+// NewCategoryRemapper creates a remapper returning fallback for transactions nothing matches
+func NewCategoryRemapper(fallback string) *CategoryRemapper {
+	return &CategoryRemapper{
+		merchants:  map[string]string{},
+		categories: map[TransactionCategory]string{},
+		groups:     map[CategoryGroup]string{},
+		fallback:   fallback,
+	}
+}
+
+// Map translates a Teller category
+func (r *CategoryRemapper) Map(category TransactionCategory, target string) *CategoryRemapper {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.categories[category] = target
+	return r
+}
+
+// MapGroup translates every category of a group that has no mapping of its own
+func (r *CategoryRemapper) MapGroup(group CategoryGroup, target string) *CategoryRemapper {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.groups[group] = target
+	return r
+}
+
+// OverrideMerchant translates every transaction of a merchant regardless of
+// its Teller category. Merchants are matched by the key of the cleaned
+// counterparty name, or description when there is none, see NormalizeMerchant.
+func (r *CategoryRemapper) OverrideMerchant(merchant, target string) *CategoryRemapper {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.merchants[NormalizeMerchant(merchant).Key] = target
+	return r
+}
+
+// RemoveMerchantOverride removes the override of a merchant
+func (r *CategoryRemapper) RemoveMerchantOverride(merchant string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.merchants, NormalizeMerchant(merchant).Key)
+}
+
+// Category returns the category of a transaction in the target taxonomy
+func (r *CategoryRemapper) Category(transaction TellerTransaction) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if target, ok := r.merchants[defaultMerchantNormalizer.Transaction(transaction).Key]; ok {
+		return target
+	}
+
+	category := transaction.Details.Category
+	if target, ok := r.categories[category]; ok {
+		return target
+	}
+	if target, ok := r.groups[category.Group()]; ok {
+		return target
+	}
+
+	return r.fallback
+}
@@ -1,10 +1,35 @@
 package teller
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"time"
 )
 
+// requestIDHeader carries the ID Teller assigns to each request, useful when contacting support
+const requestIDHeader = "X-Request-Id"
+
+// TellerError is returned when the API responds with an error status
+type TellerError struct {
+	StatusCode int    `json:"-"`
+	RequestID  string `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *TellerError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("teller: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("teller: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
 // Client is the main Teller API client
 type Client struct {
 	baseURL     string
@@ -12,6 +37,16 @@ type Client struct {
 	httpClient  *http.Client
 	certPath    string
 	keyPath     string
+	limiter     *RateLimiter
+	tokens      TokenStore
+	userID      string
+	logger      *slog.Logger
+	seen        *institutionIndex
+	certExpiry  time.Time
+	middlewares []Middleware
+	chain       Doer
+	directory   *InstitutionDirectory
+	strict      bool
 
 	// Modules
 	Identity     *IdentityModule
@@ -20,8 +55,47 @@ type Client struct {
 	Institutions *InstitutionsModule
 }
 
+// ClientOption configures optional Client behavior
+type ClientOption func(*Client)
+
+// WithRateLimit throttles requests with a global limit and a per-access-token limit, see RateLimitMiddleware
+func WithRateLimit(global, perToken RateLimit) ClientOption {
+	return func(c *Client) {
+		c.limiter = NewRateLimiter(global, perToken)
+	}
+}
+
+// WithRateLimiter throttles requests with an existing limiter, e.g. one shared by several clients
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithTokenStore sets the store that ForUser resolves access tokens from
+func WithTokenStore(store TokenStore) ClientOption {
+	return func(c *Client) {
+		c.tokens = store
+	}
+}
+
+// WithLogger logs every request and response with LoggingMiddleware
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithTransport wraps the client's mutual TLS transport, e.g. for instrumentation.
+// Wrappers see a RequestInfo in each request's context, see RequestInfoFromContext.
+func WithTransport(wrap func(http.RoundTripper) http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = wrap(c.httpClient.Transport)
+	}
+}
+
 // NewClient creates a new Teller API client
-func NewClient(certPath, keyPath string, accessToken *string) (*Client, error) {
+func NewClient(certPath, keyPath string, accessToken *string, options ...ClientOption) (*Client, error) {
 	// Load certificates for mutual TLS
 	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
 	if err != nil {
@@ -49,13 +123,131 @@ func NewClient(certPath, keyPath string, accessToken *string) (*Client, error) {
 		httpClient:  httpClient,
 		certPath:    certPath,
 		keyPath:     keyPath,
+		seen:        &institutionIndex{},
 	}
 
-	// Initialize modules
+	if cert.Leaf != nil {
+		c.certExpiry = cert.Leaf.NotAfter
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.chain = c.buildChain()
+	c.initModules()
+	c.directory = NewInstitutionDirectory(c, institutionListMaxAge)
+
+	return c, nil
+}
+
+func (c *Client) initModules() {
 	c.Identity = &IdentityModule{client: c}
 	c.Account = &AccountModule{client: c}
 	c.Transactions = &TransactionModule{client: c}
 	c.Institutions = &InstitutionsModule{client: c}
+}
 
-	return c, nil
+// CertificateExpiry returns when the client certificate expires
+func (c *Client) CertificateExpiry() time.Time {
+	return c.certExpiry
+}
+
+// RateLimiter returns the client's rate limiter, or nil if requests are not throttled
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.limiter
+}
+
+// token returns the per-request access token override, or "" to use the client's token
+func (c *Client) token(options *TellerOptionsBase) string {
+	if options != nil {
+		return options.AccessToken
+	}
+	return ""
+}
+
+// do sends a request to path and decodes the JSON response into result, which may be nil.
+// endpoint is the path template identifying the request in logs.
+// An empty token is resolved from the client, see resolveToken.
+func (c *Client) do(ctx context.Context, method string, endpoint Endpoint, path, token string, result any) error {
+	fromStore := token == "" && c.userID != ""
+
+	token, err := c.resolveToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	err = c.send(ctx, method, endpoint, path, token, result)
+	var tellerErr *TellerError
+	if fromStore && IsEnrollmentDisconnected(err) && errors.As(err, &tellerErr) {
+		if markErr := c.tokens.MarkInvalid(ctx, c.userID, tellerErr.Code); markErr != nil {
+			return errors.Join(err, markErr)
+		}
+	}
+
+	return err
+}
+
+func (c *Client) send(ctx context.Context, method string, endpoint Endpoint, path, token string, result any) error {
+	accountID := accountIDFromPath(path)
+	info := RequestInfo{
+		Method:           method,
+		Endpoint:         endpoint,
+		AccountID:        accountID,
+		InstitutionID:    c.seen.lookup(accountID),
+		UserID:           c.userID,
+		TokenFingerprint: TokenFingerprint(token),
+		Attempt:          1,
+	}
+
+	req, err := http.NewRequestWithContext(contextWithRequestInfo(ctx, info), method, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	if token != "" {
+		req.SetBasicAuth(token, "")
+	}
+
+	resp, err := c.doer().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return decodeError(resp)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return err
+	}
+
+	if c.strict {
+		if err := checkSchema(endpoint, result); err != nil {
+			return err
+		}
+	}
+
+	c.seen.record(result)
+	return nil
+}
+
+func decodeError(resp *http.Response) error {
+	tellerErr := &TellerError{StatusCode: resp.StatusCode, RequestID: resp.Header.Get(requestIDHeader)}
+
+	var body struct {
+		Error *TellerError `json:"error"`
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if json.Unmarshal(data, &body) == nil && body.Error != nil {
+		tellerErr.Code = body.Error.Code
+		tellerErr.Message = body.Error.Message
+	}
+
+	return tellerErr
 }
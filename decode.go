@@ -0,0 +1,215 @@
+package teller
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Resources keep the JSON fields this version of the library does not know
+// in their Extra field, so that fields Teller adds are not lost when a
+// resource is decoded and encoded again. Clients created with
+// WithStrictDecoding instead fail on unknown fields and enum values.
+
+// SchemaError is returned by Validate, and by clients created with WithStrictDecoding,
+// when a resource has fields or enum values this version of the library does not know
+type SchemaError struct {
+	Endpoint Endpoint
+	// Problems describe each difference, e.g. `[0].subtype: unknown value "hsa"`
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Endpoint == "" {
+		return "does not match the schema: " + strings.Join(e.Problems, "; ")
+	}
+	return fmt.Sprintf("response of %s does not match the schema: %s", e.Endpoint, strings.Join(e.Problems, "; "))
+}
+
+// WithStrictDecoding makes requests fail with a *SchemaError when a response
+// has unknown fields or enum values, e.g. to detect schema drift in contract tests
+func WithStrictDecoding() ClientOption {
+	return func(c *Client) {
+		c.strict = true
+	}
+}
+
+// schemaChecker is implemented by types that can report unknown fields and enum values
+type schemaChecker interface {
+	schemaProblems(path string) []string
+}
+
+// checkSchema returns a *SchemaError if a decoded result, a pointer to a resource or a slice of resources, has problems
+func checkSchema(endpoint Endpoint, result any) error {
+	v := reflect.Indirect(reflect.ValueOf(result))
+
+	var problems []string
+	if v.Kind() == reflect.Slice {
+		for i := range v.Len() {
+			if checker, ok := v.Index(i).Interface().(schemaChecker); ok {
+				problems = append(problems, checker.schemaProblems(fmt.Sprintf("[%d]", i))...)
+			}
+		}
+	} else if checker, ok := v.Interface().(schemaChecker); ok {
+		problems = checker.schemaProblems("")
+	}
+
+	if len(problems) > 0 {
+		return &SchemaError{Endpoint: endpoint, Problems: problems}
+	}
+	return nil
+}
+
+// unknownFieldProblems describes the fields of extra
+func unknownFieldProblems(path string, extra map[string]json.RawMessage) []string {
+	var problems []string
+	for _, name := range sortedKeys(extra) {
+		problems = append(problems, fieldPath(path, name)+": unknown field")
+	}
+	return problems
+}
+
+// unknownValueProblem describes an enum value that is not known, or returns nil.
+// Empty values are accepted, Teller sends null for values that do not apply.
+func unknownValueProblem[T ~string](path string, value T, known bool) []string {
+	if value == "" || known {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: unknown value %q", path, string(value))}
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resourceType describes a resource struct for unmarshalResource and marshalResource
+type resourceType struct {
+	// plain has the same fields as the resource but none of its methods,
+	// so that encoding/json does not call back into the resource's
+	plain reflect.Type
+	// known holds the lowercased JSON names of the fields
+	known map[string]bool
+	// extra is the index of the Extra field
+	extra int
+}
+
+var resourceTypes sync.Map // reflect.Type -> *resourceType
+
+func resourceTypeOf(t reflect.Type) *resourceType {
+	if cached, ok := resourceTypes.Load(t); ok {
+		return cached.(*resourceType)
+	}
+
+	fields := make([]reflect.StructField, t.NumField())
+	for i := range fields {
+		fields[i] = t.Field(i)
+	}
+	extra, ok := t.FieldByName("Extra")
+	if !ok {
+		panic("teller: " + t.String() + " has no Extra field")
+	}
+
+	rt := &resourceType{plain: reflect.StructOf(fields), known: knownFields(t), extra: extra.Index[0]}
+	resourceTypes.Store(t, rt)
+	return rt
+}
+
+// unmarshalResource decodes data into v, a pointer to a resource struct,
+// keeping the fields it has no field for in its Extra field
+func unmarshalResource(data []byte, v any) error {
+	rv := reflect.ValueOf(v).Elem()
+	rt := resourceTypeOf(rv.Type())
+
+	plain := reflect.New(rt.plain)
+	if err := json.Unmarshal(data, plain.Interface()); err != nil {
+		return err
+	}
+	rv.Set(plain.Elem().Convert(rv.Type()))
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		// null decodes without error but carries no fields
+		return nil
+	}
+
+	// encoding/json matches keys case-insensitively, so must the known fields
+	for name := range fields {
+		if rt.known[strings.ToLower(name)] {
+			delete(fields, name)
+		}
+	}
+	if len(fields) > 0 {
+		rv.Field(rt.extra).Set(reflect.ValueOf(fields))
+	}
+
+	return nil
+}
+
+// marshalResource encodes v, a resource struct, adding the fields of its
+// Extra field that v does not already encode
+func marshalResource(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	rt := resourceTypeOf(rv.Type())
+
+	data, err := json.Marshal(rv.Convert(rt.plain).Interface())
+	extra := rv.Field(rt.extra).Interface().(map[string]json.RawMessage)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	encoded := make(map[string]bool, len(fields))
+	for name := range fields {
+		encoded[strings.ToLower(name)] = true
+	}
+	for name, value := range extra {
+		if !encoded[strings.ToLower(name)] {
+			fields[name] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// knownFields returns the lowercased JSON names of the fields of a struct type
+func knownFields(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+
+	return names
+}
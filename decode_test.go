@@ -0,0 +1,140 @@
+package teller
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeKeepsUnknownFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		v         any
+		wantExtra map[string]string
+	}{
+		{
+			"account",
+			`{"id":"acc_1","type":"depository","nickname":"Bills","institution":{"id":"chase","name":"Chase","country":"US"}}`,
+			&TellerAccount{},
+			map[string]string{"nickname": `"Bills"`},
+		},
+		{
+			"transaction",
+			`{"id":"txn_1","amount":"-1.00","memo":"lunch","details":{"category":"dining","merchant_id":"m_1"}}`,
+			&TellerTransaction{},
+			map[string]string{"memo": `"lunch"`},
+		},
+		{"known fields match case-insensitively", `{"ID":"chase","Name":"Chase"}`, &TellerInstitution{}, map[string]string{}},
+		{"null", `null`, &TellerIdentity{}, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.data), tt.v); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			for name, value := range reflect.ValueOf(tt.v).Elem().FieldByName("Extra").Interface().(map[string]json.RawMessage) {
+				got[name] = string(value)
+			}
+			if !reflect.DeepEqual(got, tt.wantExtra) {
+				t.Errorf("Extra = %v, want %v", got, tt.wantExtra)
+			}
+
+			// Encoding again gives back every field, nested ones included
+			encoded, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var original, roundTrip any
+			json.Unmarshal([]byte(tt.data), &original)
+			json.Unmarshal(encoded, &roundTrip)
+			if !containsJSON(roundTrip, original) {
+				t.Errorf("encoded %s, want every field of %s", encoded, tt.data)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		wantProblems []string
+	}{
+		{"known", `{"id":"acc_1","type":"credit","subtype":"credit_card","status":"open"}`, nil},
+		{"null enum", `{"id":"acc_1","type":null}`, nil},
+		{
+			"unknown field and values",
+			`{"id":"acc_1","type":"brokerage","subtype":"hsa","nickname":"x","links":{"statements":"https://example.com"}}`,
+			[]string{"nickname: unknown field", `type: unknown value "brokerage"`, `subtype: unknown value "hsa"`, "links.statements: unknown field"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var account TellerAccount
+			if err := json.Unmarshal([]byte(tt.data), &account); err != nil {
+				t.Fatal(err)
+			}
+
+			err := account.Validate()
+			var schemaErr *SchemaError
+			if tt.wantProblems == nil {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("Validate() = %v, want a *SchemaError", err)
+			}
+			if !sameElements(schemaErr.Problems, tt.wantProblems) {
+				t.Errorf("Problems = %q, want %q", schemaErr.Problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+// containsJSON reports whether the decoded JSON got has every field of want,
+// matching object keys case-insensitively like encoding/json
+func containsJSON(got, want any) bool {
+	wantObject, ok := want.(map[string]any)
+	if !ok {
+		return want == nil || reflect.DeepEqual(got, want)
+	}
+
+	gotObject, ok := got.(map[string]any)
+	if !ok {
+		return false
+	}
+	for name, value := range wantObject {
+		if !containsJSON(gotObject[strings.ToLower(name)], value) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameElements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := map[string]int{}
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		counts[s]--
+	}
+	for _, n := range counts {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
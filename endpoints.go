@@ -0,0 +1,16 @@
+package teller
+
+// Endpoint is the path template of an API endpoint, used to identify
+// requests in logs and metrics without the IDs they contain
+type Endpoint = string
+
+const (
+	EndpointAccounts        Endpoint = "/accounts"
+	EndpointAccount         Endpoint = "/accounts/{id}"
+	EndpointAccountDetails  Endpoint = "/accounts/{id}/details"
+	EndpointAccountBalances Endpoint = "/accounts/{id}/balances"
+	EndpointTransactions    Endpoint = "/accounts/{id}/transactions"
+	EndpointTransaction     Endpoint = "/accounts/{id}/transactions/{transaction_id}"
+	EndpointIdentity        Endpoint = "/identity"
+	EndpointInstitutions    Endpoint = "/institutions"
+)
@@ -29,7 +29,11 @@ func main() {
 
 	for _, institution := range institutions {
 		fmt.Printf("Institution: %s (ID: %s)\n", institution.Name, institution.ID)
-		fmt.Println("Products:", strings.Join(institution.Products, ", "))
+		products := make([]string, len(institution.Products))
+		for i, product := range institution.Products {
+			products[i] = string(product)
+		}
+		fmt.Println("Products:", strings.Join(products, ", "))
 	}
 
 	accounts, err := client.Account.List(&teller.TellerOptionsBase{
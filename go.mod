@@ -1,3 +1,14 @@
 module github.com/maxint-app/teller-go
 
 go 1.25.3
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.52
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/rogpeppe/go-internal v1.13.1 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+)
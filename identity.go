@@ -1,8 +1,9 @@
 package teller
 
 import (
+	"context"
 	"encoding/json"
-	"net/http"
+	"fmt"
 )
 
 // TellerAddress represents an address associated with an identity
@@ -13,29 +14,157 @@ type TellerAddress struct {
 	Region      string `json:"region"`
 	PostalCode  string `json:"postal_code"`
 	CountryCode string `json:"country_code"`
+	// Extra holds address fields this version of the library does not know
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// OwnerName is a name of an account owner
+type OwnerName struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+	// Extra holds name fields this version of the library does not know
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// PhoneNumber is a phone number of an account owner
+type PhoneNumber struct {
+	Type string `json:"type"` // "mobile", "home", "work", "unknown"
+	Data string `json:"data"`
+	// Extra holds phone number fields this version of the library does not know
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Email is an email address of an account owner
+type Email struct {
+	Data string `json:"data"`
+	// Extra holds email fields this version of the library does not know
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // TellerOwner represents the owner of an account
 type TellerOwner struct {
-	Type  string `json:"type"` // "person" or "business"
-	Names []struct {
-		Type string `json:"type"`
-		Data string `json:"data"`
-	} `json:"names"`
+	Type         string          `json:"type"` // "person" or "business"
+	Names        []OwnerName     `json:"names"`
 	Addresses    []TellerAddress `json:"addresses"`
-	PhoneNumbers []struct {
-		Type string `json:"type"` // "mobile", "home", "work", "unknown"
-		Data string `json:"data"`
-	} `json:"phone_numbers"`
-	Emails []struct {
-		Data string `json:"data"`
-	} `json:"emails"`
+	PhoneNumbers []PhoneNumber   `json:"phone_numbers"`
+	Emails       []Email         `json:"emails"`
+	// Extra holds owner fields added to the API after this version of the library
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // TellerIdentity represents identity information for an account
 type TellerIdentity struct {
 	Account TellerAccount `json:"account"`
 	Owners  []TellerOwner `json:"owners"`
+	// Extra holds identity fields added to the API after this version of the library
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the owner, keeping unknown fields in Extra
+func (o *TellerOwner) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, o)
+}
+
+// MarshalJSON encodes the owner with the unknown fields kept in Extra
+func (o TellerOwner) MarshalJSON() ([]byte, error) {
+	return marshalResource(o)
+}
+
+func (o TellerOwner) schemaProblems(path string) []string {
+	problems := unknownFieldProblems(path, o.Extra)
+	for n, name := range o.Names {
+		problems = append(problems, name.schemaProblems(fmt.Sprintf("%s[%d]", fieldPath(path, "names"), n))...)
+	}
+	for n, address := range o.Addresses {
+		problems = append(problems, address.schemaProblems(fmt.Sprintf("%s[%d]", fieldPath(path, "addresses"), n))...)
+	}
+	for n, phone := range o.PhoneNumbers {
+		problems = append(problems, phone.schemaProblems(fmt.Sprintf("%s[%d]", fieldPath(path, "phone_numbers"), n))...)
+	}
+	for n, email := range o.Emails {
+		problems = append(problems, email.schemaProblems(fmt.Sprintf("%s[%d]", fieldPath(path, "emails"), n))...)
+	}
+	return problems
+}
+
+// UnmarshalJSON decodes the address, keeping unknown fields in Extra
+func (a *TellerAddress) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, a)
+}
+
+// MarshalJSON encodes the address with the unknown fields kept in Extra
+func (a TellerAddress) MarshalJSON() ([]byte, error) {
+	return marshalResource(a)
+}
+
+func (a TellerAddress) schemaProblems(path string) []string {
+	return unknownFieldProblems(path, a.Extra)
+}
+
+// UnmarshalJSON decodes the name, keeping unknown fields in Extra
+func (n *OwnerName) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, n)
+}
+
+// MarshalJSON encodes the name with the unknown fields kept in Extra
+func (n OwnerName) MarshalJSON() ([]byte, error) {
+	return marshalResource(n)
+}
+
+func (n OwnerName) schemaProblems(path string) []string {
+	return unknownFieldProblems(path, n.Extra)
+}
+
+// UnmarshalJSON decodes the phone number, keeping unknown fields in Extra
+func (p *PhoneNumber) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, p)
+}
+
+// MarshalJSON encodes the phone number with the unknown fields kept in Extra
+func (p PhoneNumber) MarshalJSON() ([]byte, error) {
+	return marshalResource(p)
+}
+
+func (p PhoneNumber) schemaProblems(path string) []string {
+	return unknownFieldProblems(path, p.Extra)
+}
+
+// UnmarshalJSON decodes the email, keeping unknown fields in Extra
+func (e *Email) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, e)
+}
+
+// MarshalJSON encodes the email with the unknown fields kept in Extra
+func (e Email) MarshalJSON() ([]byte, error) {
+	return marshalResource(e)
+}
+
+func (e Email) schemaProblems(path string) []string {
+	return unknownFieldProblems(path, e.Extra)
+}
+
+// UnmarshalJSON decodes the identity, keeping unknown fields in Extra
+func (i *TellerIdentity) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, i)
+}
+
+// MarshalJSON encodes the identity with the unknown fields kept in Extra
+func (i TellerIdentity) MarshalJSON() ([]byte, error) {
+	return marshalResource(i)
+}
+
+// Validate returns a *SchemaError if the identity has unknown fields or enum values
+func (i TellerIdentity) Validate() error {
+	return checkSchema("", i)
+}
+
+func (i TellerIdentity) schemaProblems(path string) []string {
+	problems := unknownFieldProblems(path, i.Extra)
+	problems = append(problems, i.Account.schemaProblems(fieldPath(path, "account"))...)
+	for n, owner := range i.Owners {
+		problems = append(problems, owner.schemaProblems(fmt.Sprintf("%s[%d]", fieldPath(path, "owners"), n))...)
+	}
+	return problems
 }
 
 // IdentityModule handles identity-related API calls
@@ -45,25 +174,13 @@ type IdentityModule struct {
 
 // Get retrieves identity information
 func (m *IdentityModule) Get(options *TellerOptionsBase) ([]TellerIdentity, error) {
-	req, err := http.NewRequest("GET", m.client.baseURL+"/identity", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if options != nil && options.AccessToken != "" {
-		req.SetBasicAuth(options.AccessToken, "")
-	} else if m.client.accessToken != "" {
-		req.SetBasicAuth(m.client.accessToken, "")
-	}
-
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return m.GetContext(context.Background(), options)
+}
 
+// GetContext is Get with a context, which cancels the request and any wait for the rate limiter
+func (m *IdentityModule) GetContext(ctx context.Context, options *TellerOptionsBase) ([]TellerIdentity, error) {
 	var result []TellerIdentity
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := m.client.do(ctx, "GET", EndpointIdentity, "/identity", m.client.token(options), &result); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,414 @@
+package teller
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidPhoneNumber is returned by NormalizePhone for numbers that cannot be formatted as E.164
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// Applicant is the identity a user entered, to be compared with the owners Teller reports
+type Applicant struct {
+	Name    string
+	Email   string
+	Phone   string
+	Address *TellerAddress
+}
+
+// FieldMatch is how well one applicant field matches an owner, Score ranging from 0 to 1.
+// Compared is false when either side had no value, in which case Score is 0.
+type FieldMatch struct {
+	Score    float64 `json:"score"`
+	Compared bool    `json:"compared"`
+}
+
+// OwnerMatch holds per-field match scores between an owner and an applicant
+type OwnerMatch struct {
+	Name    FieldMatch `json:"name"`
+	Email   FieldMatch `json:"email"`
+	Phone   FieldMatch `json:"phone"`
+	Address FieldMatch `json:"address"`
+}
+
+// IdentityMatcher compares owners to applicants
+type IdentityMatcher struct {
+	// DefaultCountryCode is the calling code assumed for phone numbers without one, "1" if empty
+	DefaultCountryCode string
+}
+
+// Match compares an owner to an applicant. Owners list several names, emails,
+// phone numbers and addresses; each field is scored against the best of them.
+func (m IdentityMatcher) Match(owner TellerOwner, applicant Applicant) OwnerMatch {
+	var result OwnerMatch
+
+	if applicant.Name != "" {
+		for _, name := range owner.Names {
+			result.Name = bestMatch(result.Name, NameSimilarity(applicant.Name, name.Data))
+		}
+	}
+
+	if applicant.Email != "" {
+		for _, email := range owner.Emails {
+			result.Email = bestMatch(result.Email, emailSimilarity(applicant.Email, email.Data))
+		}
+	}
+
+	if applicant.Phone != "" {
+		for _, phone := range owner.PhoneNumbers {
+			result.Phone = bestMatch(result.Phone, m.phoneSimilarity(applicant.Phone, phone.Data))
+		}
+	}
+
+	if applicant.Address != nil {
+		for _, address := range owner.Addresses {
+			result.Address = bestMatch(result.Address, AddressSimilarity(*applicant.Address, address))
+		}
+	}
+
+	return result
+}
+
+// MatchOwner compares an owner to an applicant, assuming phone numbers without a country code are North American
+func MatchOwner(owner TellerOwner, applicant Applicant) OwnerMatch {
+	return IdentityMatcher{}.Match(owner, applicant)
+}
+
+func bestMatch(current FieldMatch, score float64) FieldMatch {
+	return FieldMatch{Score: max(current.Score, score), Compared: true}
+}
+
+// NormalizeEmail trims and lowercases an email address
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func emailSimilarity(a, b string) float64 {
+	a, b = NormalizeEmail(a), NormalizeEmail(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	// Same mailbox name at another provider is weak evidence
+	localA, _, _ := strings.Cut(a, "@")
+	localB, _, _ := strings.Cut(b, "@")
+	if localA == localB {
+		return 0.5
+	}
+
+	return 0
+}
+
+// NormalizePhone formats a phone number as E.164, e.g. "+14155550123".
+// Numbers without a leading + or 00 prefix are assumed to be in the
+// country with calling code defaultCountryCode, "1" if empty.
+func NormalizePhone(phone, defaultCountryCode string) (string, error) {
+	if defaultCountryCode == "" {
+		defaultCountryCode = "1"
+	}
+
+	s := strings.TrimSpace(phone)
+	// Drop extensions such as "x123" or "ext. 123"
+	if i := strings.IndexFunc(s, func(r rune) bool { return unicode.IsLetter(r) }); i >= 0 {
+		s = s[:i]
+	}
+
+	international := strings.HasPrefix(s, "+")
+
+	var digits strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+
+	if !international && strings.HasPrefix(number, "00") {
+		international = true
+		number = number[2:]
+	}
+
+	if !international {
+		switch {
+		case defaultCountryCode == "1" && len(number) == 11 && number[0] == '1':
+			// Already carries the NANP country code
+		case defaultCountryCode != "1" && strings.HasPrefix(number, "0"):
+			// Drop the trunk prefix used for national dialing
+			number = defaultCountryCode + number[1:]
+		default:
+			number = defaultCountryCode + number
+		}
+	}
+
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", ErrInvalidPhoneNumber
+	}
+
+	return "+" + number, nil
+}
+
+func (m IdentityMatcher) phoneSimilarity(a, b string) float64 {
+	normalizedA, errA := NormalizePhone(a, m.DefaultCountryCode)
+	normalizedB, errB := NormalizePhone(b, m.DefaultCountryCode)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	if normalizedA == normalizedB {
+		return 1
+	}
+
+	// The same subscriber number under a different country code assumption
+	if len(normalizedA) >= 11 && len(normalizedB) >= 11 && normalizedA[len(normalizedA)-10:] == normalizedB[len(normalizedB)-10:] {
+		return 0.8
+	}
+
+	return 0
+}
+
+var nameAffixes = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "miss": true, "mx": true, "dr": true, "prof": true,
+	"jr": true, "sr": true, "ii": true, "iii": true, "iv": true, "esq": true, "md": true, "phd": true,
+}
+
+// NameTokens splits a name into lowercase tokens without accents, punctuation,
+// titles or generational suffixes, e.g. "Dr. José O'Neil Jr." gives [jose oneil]
+func NameTokens(name string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '-' || r == '/'
+	}) {
+		var b strings.Builder
+		for _, r := range foldAccents(strings.ToLower(word)) {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(r)
+			}
+		}
+
+		token := b.String()
+		if token != "" && !nameAffixes[token] {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// NameSimilarity scores two names from 0 to 1. Token order does not matter,
+// an initial matches the token it abbreviates and small typos are tolerated.
+// Tokens of a missing middle name lower the score only slightly.
+func NameSimilarity(a, b string) float64 {
+	tokensA, tokensB := NameTokens(a), NameTokens(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+
+	coverageA := tokenCoverage(tokensA, tokensB)
+	coverageB := tokenCoverage(tokensB, tokensA)
+
+	return 0.7*min(coverageA, coverageB) + 0.3*max(coverageA, coverageB)
+}
+
+// tokenCoverage averages, for each token of a, its best similarity to a
+// token of b. Initials weigh less, so a missing middle initial barely counts.
+func tokenCoverage(a, b []string) float64 {
+	total, weights := 0.0, 0.0
+	for _, tokenA := range a {
+		best := 0.0
+		for _, tokenB := range b {
+			best = max(best, tokenSimilarity(tokenA, tokenB))
+		}
+
+		weight := 1.0
+		if len([]rune(tokenA)) == 1 {
+			weight = 0.25
+		}
+		total += weight * best
+		weights += weight
+	}
+	return total / weights
+}
+
+func tokenSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	runesA, runesB := []rune(a), []rune(b)
+	if (len(runesA) == 1 || len(runesB) == 1) && runesA[0] == runesB[0] {
+		return 0.8
+	}
+
+	longest := max(len(runesA), len(runesB))
+	distance := editDistance(a, b)
+	if distance > maxTypos(string(runesA)) && distance > maxTypos(string(runesB)) {
+		return 0
+	}
+
+	return 1 - float64(distance)/float64(longest)
+}
+
+var accentFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c",
+	'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i",
+	'ł': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ř': "r",
+	'ś': "s", 'š': "s", 'ß': "ss",
+	'ť': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+	'æ': "ae", 'œ': "oe",
+}
+
+// foldAccents replaces accented latin letters of a lowercase string with their base letters
+func foldAccents(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if folded, ok := accentFolds[r]; ok {
+			b.WriteString(folded)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var streetAbbreviations = map[string]string{
+	"street": "st", "avenue": "ave", "av": "ave", "boulevard": "blvd", "road": "rd", "drive": "dr",
+	"lane": "ln", "court": "ct", "place": "pl", "terrace": "ter", "circle": "cir", "highway": "hwy",
+	"parkway": "pkwy", "square": "sq", "trail": "trl", "way": "way", "expressway": "expy",
+	"north": "n", "south": "s", "east": "e", "west": "w",
+	"northeast": "ne", "northwest": "nw", "southeast": "se", "southwest": "sw",
+	"apartment": "apt", "suite": "ste", "unit": "unit", "floor": "fl", "building": "bldg", "room": "rm",
+	"#": "unit",
+}
+
+var usStates = map[string]string{
+	"alabama": "AL", "alaska": "AK", "arizona": "AZ", "arkansas": "AR", "california": "CA",
+	"colorado": "CO", "connecticut": "CT", "delaware": "DE", "district of columbia": "DC",
+	"florida": "FL", "georgia": "GA", "hawaii": "HI", "idaho": "ID", "illinois": "IL",
+	"indiana": "IN", "iowa": "IA", "kansas": "KS", "kentucky": "KY", "louisiana": "LA",
+	"maine": "ME", "maryland": "MD", "massachusetts": "MA", "michigan": "MI", "minnesota": "MN",
+	"mississippi": "MS", "missouri": "MO", "montana": "MT", "nebraska": "NE", "nevada": "NV",
+	"new hampshire": "NH", "new jersey": "NJ", "new mexico": "NM", "new york": "NY",
+	"north carolina": "NC", "north dakota": "ND", "ohio": "OH", "oklahoma": "OK", "oregon": "OR",
+	"pennsylvania": "PA", "rhode island": "RI", "south carolina": "SC", "south dakota": "SD",
+	"tennessee": "TN", "texas": "TX", "utah": "UT", "vermont": "VT", "virginia": "VA",
+	"washington": "WA", "west virginia": "WV", "wisconsin": "WI", "wyoming": "WY",
+	"puerto rico": "PR",
+}
+
+// NormalizeAddress standardizes an address for comparison: the street is
+// lowercased with USPS-style abbreviations, US state names become their
+// two-letter codes, US ZIP+4 codes are cut to five digits and the country
+// code is uppercased
+func NormalizeAddress(address TellerAddress) TellerAddress {
+	result := address
+	result.CountryCode = strings.ToUpper(strings.TrimSpace(address.CountryCode))
+
+	var street []string
+	for _, word := range strings.Fields(foldAccents(strings.ToLower(address.Street))) {
+		word = strings.Trim(word, ".,")
+		if abbreviation, ok := streetAbbreviations[word]; ok {
+			word = abbreviation
+		}
+		if number, ok := strings.CutPrefix(word, "#"); ok && number != "" {
+			street = append(street, "unit", number)
+			continue
+		}
+		if word != "" {
+			street = append(street, word)
+		}
+	}
+	result.Street = strings.Join(street, " ")
+
+	result.City = normalizeName(foldAccents(address.City))
+
+	region := normalizeName(address.Region)
+	if code, ok := usStates[region]; ok {
+		result.Region = code
+	} else {
+		result.Region = strings.ToUpper(region)
+	}
+
+	postalCode := strings.ToUpper(strings.TrimSpace(address.PostalCode))
+	if result.CountryCode == "US" || result.CountryCode == "" {
+		postalCode, _, _ = strings.Cut(postalCode, "-")
+		if len(postalCode) == 9 {
+			postalCode = postalCode[:5]
+		}
+	}
+	result.PostalCode = strings.ReplaceAll(postalCode, " ", "")
+
+	return result
+}
+
+// AddressSimilarity scores two addresses from 0 to 1, weighing the street
+// most, then the postal code, city and region
+func AddressSimilarity(a, b TellerAddress) float64 {
+	a, b = NormalizeAddress(a), NormalizeAddress(b)
+	if a.CountryCode != "" && b.CountryCode != "" && a.CountryCode != b.CountryCode {
+		return 0
+	}
+
+	score := 0.0
+	if a.Street != "" && b.Street != "" {
+		score += 0.4 * streetSimilarity(a.Street, b.Street)
+	}
+	if a.PostalCode != "" && a.PostalCode == b.PostalCode {
+		score += 0.3
+	}
+	if a.City != "" && b.City != "" {
+		score += 0.2 * tokenSimilarity(strings.ReplaceAll(a.City, " ", ""), strings.ReplaceAll(b.City, " ", ""))
+	}
+	if a.Region != "" && a.Region == b.Region {
+		score += 0.1
+	}
+
+	return score
+}
+
+// streetSimilarity requires the house numbers to agree and compares the rest by name similarity
+func streetSimilarity(a, b string) float64 {
+	numberA, restA, _ := strings.Cut(a, " ")
+	numberB, restB, _ := strings.Cut(b, " ")
+
+	if startsWithDigit(numberA) || startsWithDigit(numberB) {
+		if numberA != numberB {
+			return 0
+		}
+		a, b = restA, restB
+	}
+
+	tokensA, tokensB := streetTokens(a), streetTokens(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+
+	return min(tokenCoverage(tokensA, tokensB), tokenCoverage(tokensB, tokensA))
+}
+
+var unitDesignators = map[string]bool{"apt": true, "ste": true, "unit": true, "fl": true, "bldg": true, "rm": true}
+
+// streetTokens drops unit designators, which are used interchangeably, keeping the unit numbers
+func streetTokens(street string) []string {
+	var tokens []string
+	for _, token := range strings.Fields(street) {
+		if !unitDesignators[token] {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
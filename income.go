@@ -0,0 +1,324 @@
+package teller
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// incomeCadenceSpecs are the pay frequencies. Semimonthly and biweekly pay
+// both come about every fifteen days, so semimonthly is tried first and
+// only matches pay on fixed days of the month that a fourteen-day cadence
+// does not fit, see isSemimonthly. Pay moved by a holiday shortens an interval.
+var incomeCadenceSpecs = []cadenceSpec{
+	{cadence: CadenceWeekly, min: 6, max: 8, grace: 3, minOccurrences: 3},
+	{cadence: CadenceSemimonthly, min: 11, max: 19, grace: 5, minOccurrences: 4, dates: isSemimonthly},
+	{cadence: CadenceBiweekly, min: 11, max: 17, grace: 5, minOccurrences: 3},
+	{cadence: CadenceMonthly, min: 27, max: 34, grace: 7, minOccurrences: 2},
+}
+
+// paysPerMonth converts pay frequencies into monthly amounts
+var paysPerMonth = map[Cadence]float64{
+	CadenceWeekly:      52.0 / 12,
+	CadenceBiweekly:    26.0 / 12,
+	CadenceSemimonthly: 2,
+	CadenceMonthly:     1,
+}
+
+var (
+	payrollPattern = regexp.MustCompile(`\b(PAYROLL|PAYRLL|PYRL|DIRECT DEP(OSIT)?|DIR DEP|DIRDEP|SALARY|PAYCHECK|WAGES?|ADP|GUSTO|PAYCHEX|TRINET|INTUIT PAYROLL|RIPPLING|JUSTWORKS|REG\.? SALARY)\b`)
+	// nonIncomePattern matches credits that are not earned income
+	nonIncomePattern = regexp.MustCompile(`\b(TRANSFER|XFER|TFR|REFUND|REVERSAL|RETURN|CASHBACK|CASH BACK|INTEREST|DIVIDEND|ZELLE|VENMO|CASH APP|PAYPAL|ATM|MOBILE DEPOSIT|DEPOSIT AT|BRANCH)\b`)
+	// payrollNoise is removed from descriptions to find the employer
+	payrollNoise = regexp.MustCompile(`\b(PPD|CCD|WEB|CO ID|ID|INDN|DES|ENTRY|DESCR|TRACE)\b.*$|\b(PAYROLL|PAYRLL|PYRL|DIRECT DEPOSIT|DIRECT DEP|DIR DEP|DIRDEP|SALARY|PAYCHECK|WAGES?|REG\.? SALARY|PAYMENT|PAY|DEP|DEPOSIT|ACH|CREDIT)\b`)
+)
+
+// IncomeOptions tunes AnalyzeIncome
+type IncomeOptions struct {
+	// Now is the date streams are judged active against, defaults to today
+	Now time.Time
+	// Normalizer extracts employer names, defaults to NormalizeMerchant's
+	Normalizer *MerchantNormalizer
+}
+
+// IncomeStream is a series of deposits from one payer
+type IncomeStream struct {
+	// Employer is the payer's cleaned name, Key identifies it
+	Employer string
+	Key      string
+	// Payroll is set when the descriptions mention payroll or direct deposit
+	Payroll bool
+	// Frequency is the pay cadence, empty for irregular deposits
+	Frequency Cadence
+	// Deposits are oldest first
+	Deposits      []TellerTransaction
+	AccountIDs    []string
+	LastDate      time.Time
+	AverageAmount Amount
+	// MonthlyAmount is the average deposit scaled to a month by frequency,
+	// or the total of irregular deposits divided by the months observed
+	MonthlyAmount Amount
+	// Stability is 1 for identical amounts, decreasing towards 0 as amounts vary
+	Stability float64
+	// Regularity is the share of intervals between deposits that match Frequency
+	Regularity float64
+	// Active is set when a deposit is still expected, i.e. the last one is not overdue
+	Active bool
+	// Confidence that the stream is recurring earned income, from 0 to 1
+	Confidence float64
+}
+
+// IncomeReport summarizes the income streams of a user
+type IncomeReport struct {
+	// Streams are ordered by monthly amount, largest first
+	Streams []IncomeStream
+	// MonthlyIncome is the sum of the monthly amounts of active streams
+	MonthlyIncome Amount
+	// Confidence averages the confidence of active streams, weighted by monthly amount
+	Confidence float64
+}
+
+// AnalyzeIncome identifies income streams among the credits of depository
+// accounts. Transactions of other accounts, pending transactions and
+// credits such as transfers, refunds and interest are ignored.
+func AnalyzeIncome(accounts []TellerAccount, transactions []TellerTransaction, options *IncomeOptions) IncomeReport {
+	if options == nil {
+		options = &IncomeOptions{}
+	}
+
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	normalizer := options.Normalizer
+	if normalizer == nil {
+		normalizer = defaultMerchantNormalizer
+	}
+
+	depository := map[string]bool{}
+	for _, account := range accounts {
+		if account.Type == TellerAccountTypeDepository {
+			depository[account.ID] = true
+		}
+	}
+
+	groups := map[string][]recurringCharge{}
+	employers := map[string]string{}
+	var earliest time.Time
+	for _, transaction := range transactions {
+		if !depository[transaction.AccountID] || transaction.Status == TellerTransactionStatusTypePending {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, transaction.Date)
+		if err != nil {
+			continue
+		}
+		if earliest.IsZero() || date.Before(earliest) {
+			earliest = date
+		}
+
+		amount, err := ParseAmount(transaction.Amount)
+		if err != nil || amount <= 0 {
+			continue
+		}
+
+		description := strings.ToUpper(transaction.Description)
+		if nonIncomePattern.MatchString(description) && !payrollPattern.MatchString(description) {
+			continue
+		}
+
+		employer := incomePayer(normalizer, transaction)
+		if employer.Key == "" {
+			continue
+		}
+
+		groups[employer.Key] = append(groups[employer.Key], recurringCharge{transaction: transaction, date: date, amount: amount})
+		employers[employer.Key] = employer.Name
+	}
+
+	var report IncomeReport
+	for key, deposits := range groups {
+		sort.SliceStable(deposits, func(i, j int) bool { return deposits[i].date.Before(deposits[j].date) })
+
+		stream, ok := incomeStream(deposits, now, earliest)
+		if !ok {
+			continue
+		}
+		stream.Employer = employers[key]
+		stream.Key = key
+		report.Streams = append(report.Streams, stream)
+	}
+
+	sort.Slice(report.Streams, func(i, j int) bool {
+		if report.Streams[i].MonthlyAmount != report.Streams[j].MonthlyAmount {
+			return report.Streams[i].MonthlyAmount > report.Streams[j].MonthlyAmount
+		}
+		return report.Streams[i].Key < report.Streams[j].Key
+	})
+
+	weighted := 0.0
+	for _, stream := range report.Streams {
+		if stream.Active {
+			report.MonthlyIncome += stream.MonthlyAmount
+			weighted += stream.Confidence * float64(stream.MonthlyAmount)
+		}
+	}
+	if report.MonthlyIncome > 0 {
+		report.Confidence = weighted / float64(report.MonthlyIncome)
+	}
+
+	return report
+}
+
+// incomePayer names the payer of a deposit from its counterparty, or its description without payroll terms
+func incomePayer(normalizer *MerchantNormalizer, transaction TellerTransaction) Merchant {
+	if name := transaction.Details.Counterparty.Name; name != nil && *name != "" {
+		return normalizer.Normalize(*name)
+	}
+
+	description := strings.ToUpper(transaction.Description)
+	if cleaned := strings.TrimSpace(payrollNoise.ReplaceAllString(description, " ")); cleaned != "" {
+		description = cleaned
+	}
+	return normalizer.Normalize(description)
+}
+
+// incomeStream describes deposits from one payer sorted by date, if they
+// look like income: a pay frequency or payroll descriptions
+func incomeStream(deposits []recurringCharge, now, earliest time.Time) (IncomeStream, bool) {
+	stream := IncomeStream{LastDate: deposits[len(deposits)-1].date}
+
+	total := Amount(0)
+	accounts := map[string]bool{}
+	for _, deposit := range deposits {
+		stream.Deposits = append(stream.Deposits, deposit.transaction)
+		if !accounts[deposit.transaction.AccountID] {
+			accounts[deposit.transaction.AccountID] = true
+			stream.AccountIDs = append(stream.AccountIDs, deposit.transaction.AccountID)
+		}
+		if payrollPattern.MatchString(strings.ToUpper(deposit.transaction.Description)) {
+			stream.Payroll = true
+		}
+		total += deposit.amount
+	}
+	stream.AverageAmount = total / Amount(len(deposits))
+	stream.Stability = amountStability(deposits)
+
+	grace := 45
+	if len(deposits) >= 2 {
+		if intervals, ok := chargeIntervals(deposits); ok {
+			if spec, ok := matchCadence(deposits, intervals, incomeCadenceSpecs); ok && len(deposits) >= spec.minOccurrences {
+				stream.Frequency = spec.cadence
+				stream.Regularity = regularity(intervals, spec)
+				grace = spec.max + spec.grace
+			}
+		}
+	}
+
+	if stream.Frequency == "" && (!stream.Payroll || len(deposits) < 2) {
+		return IncomeStream{}, false
+	}
+
+	if stream.Frequency != "" {
+		stream.MonthlyAmount = Amount(math.Round(float64(stream.AverageAmount) * paysPerMonth[stream.Frequency]))
+	} else {
+		months := max(now.Sub(earliest).Hours()/24/30.44, 1)
+		stream.MonthlyAmount = Amount(math.Round(float64(total) / months))
+	}
+
+	stream.Active = !now.After(stream.LastDate.AddDate(0, 0, grace))
+
+	confidence := 0.0
+	if stream.Payroll {
+		confidence += 0.4
+	}
+	if stream.Frequency != "" {
+		confidence += 0.3 * stream.Regularity
+	}
+	confidence += 0.2 * stream.Stability
+	confidence += 0.1 * min(float64(len(deposits))/6, 1)
+	stream.Confidence = math.Round(confidence*100) / 100
+
+	return stream, true
+}
+
+// amountStability is one minus the coefficient of variation of the amounts, at least 0
+func amountStability(deposits []recurringCharge) float64 {
+	mean := 0.0
+	for _, deposit := range deposits {
+		mean += float64(deposit.amount)
+	}
+	mean /= float64(len(deposits))
+
+	variance := 0.0
+	for _, deposit := range deposits {
+		variance += math.Pow(float64(deposit.amount)-mean, 2)
+	}
+	variance /= float64(len(deposits))
+
+	return math.Round(max(1-math.Sqrt(variance)/mean, 0)*100) / 100
+}
+
+// isSemimonthly reports whether deposits sorted by date alternate between two
+// days of the month, such as the 1st and the 15th, give or take a weekend,
+// and do not fit a fourteen-day cadence. Every other biweekly deposit is 28
+// days apart and can fall near the same day of the month for months, so a
+// series fitting both, e.g. four deposits from Feb 1, counts as biweekly.
+func isSemimonthly(deposits []recurringCharge) bool {
+	if len(deposits) < 4 || fitsBiweekly(deposits) {
+		return false
+	}
+
+	// Every other deposit falls within two days of one day of the month
+	onDay := func(first, day int) bool {
+		for i := first; i < len(deposits); i += 2 {
+			if dayOfMonthDistance(deposits[i].date, day) > 2 {
+				return false
+			}
+		}
+		return true
+	}
+
+	for first := range 2 {
+		found := false
+		for day := 1; day <= 31 && !found; day++ {
+			found = onDay(first, day)
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fitsBiweekly reports whether every deposit falls within a day of a
+// fourteen-day grid starting at the first one, skipped periods included
+func fitsBiweekly(deposits []recurringCharge) bool {
+	first := deposits[0].date
+	for _, deposit := range deposits[1:] {
+		days := int(math.Round(deposit.date.Sub(first).Hours() / 24))
+		if offset := (days + 7) % 14; offset < 6 || offset > 8 {
+			return false
+		}
+	}
+	return true
+}
+
+// dayOfMonthDistance returns the days between date and the closest
+// occurrence of a day of the month, clamped to the end of shorter months
+func dayOfMonthDistance(date time.Time, day int) int {
+	distance := math.MaxInt
+	firstOfMonth := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	for months := -1; months <= 1; months++ {
+		month := firstOfMonth.AddDate(0, months, 0)
+		anchor := month.AddDate(0, 0, min(day, month.AddDate(0, 1, -1).Day())-1)
+		days := int(math.Round(math.Abs(date.Sub(anchor).Hours() / 24)))
+		distance = min(distance, days)
+	}
+	return distance
+}
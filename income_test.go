@@ -0,0 +1,157 @@
+package teller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testTransactions returns posted transactions of accountID on dates with the same description and amount
+func testTransactions(accountID, description, amount string, dates []time.Time) []TellerTransaction {
+	transactions := make([]TellerTransaction, len(dates))
+	for i, date := range dates {
+		transactions[i] = TellerTransaction{
+			ID:          fmt.Sprintf("txn_%s_%d", accountID, i),
+			AccountID:   accountID,
+			Amount:      amount,
+			Date:        date.Format(dateLayout),
+			Description: description,
+			Status:      TellerTransactionStatusTypePosted,
+		}
+	}
+	return transactions
+}
+
+// everyDays returns n dates days apart from start
+func everyDays(start time.Time, days, n int) []time.Time {
+	dates := make([]time.Time, n)
+	for i := range dates {
+		dates[i] = start.AddDate(0, 0, i*days)
+	}
+	return dates
+}
+
+// semimonthlyDates returns n dates alternating between day and the last day of the month from start's month
+func semimonthlyDates(start time.Time, day, n int) []time.Time {
+	var dates []time.Time
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); len(dates) < n; month = month.AddDate(0, 1, 0) {
+		dates = append(dates, month.AddDate(0, 0, day-1))
+		if len(dates) < n {
+			dates = append(dates, month.AddDate(0, 1, -1))
+		}
+	}
+	return dates
+}
+
+func TestAnalyzeIncomeFrequency(t *testing.T) {
+	accounts := []TellerAccount{{ID: "acc_1", Type: TellerAccountTypeDepository}}
+
+	type incomeCase struct {
+		name    string
+		dates   []time.Time
+		want    Cadence
+		monthly Amount
+	}
+	var tests []incomeCase
+
+	// Biweekly pay is never taken for semimonthly pay, whatever its start date
+	for start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); start.Year() == 2025; start = start.AddDate(0, 0, 3) {
+		for n := 4; n <= 8; n++ {
+			tests = append(tests, incomeCase{
+				fmt.Sprintf("biweekly %d from %s", n, start.Format(dateLayout)),
+				everyDays(start, 14, n), CadenceBiweekly, 433333,
+			})
+		}
+	}
+
+	// Semimonthly pay on the 1st and the 15th, and on the 15th and the last day.
+	// Fewer deposits can fit a fourteen-day cadence as well, e.g. from Feb 1.
+	for month := 1; month <= 12; month++ {
+		for n := 6; n <= 8; n++ {
+			start := time.Date(2025, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+			tests = append(tests, incomeCase{
+				fmt.Sprintf("semimonthly 15th and last %d from %s", n, start.Format("2006-01")),
+				semimonthlyDates(start, 15, n), CadenceSemimonthly, 400000,
+			})
+
+			var firstAndFifteenth []time.Time
+			for d := start; len(firstAndFifteenth) < n; d = d.AddDate(0, 1, 0) {
+				firstAndFifteenth = append(firstAndFifteenth, d, d.AddDate(0, 0, 14))
+			}
+			tests = append(tests, incomeCase{
+				fmt.Sprintf("semimonthly 1st and 15th %d from %s", n, start.Format("2006-01")),
+				firstAndFifteenth[:n], CadenceSemimonthly, 400000,
+			})
+		}
+	}
+
+	tests = append(tests,
+		incomeCase{"weekly", everyDays(time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC), 7, 8), CadenceWeekly, 866667},
+		incomeCase{"monthly", []time.Time{
+			time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		}, CadenceMonthly, 200000},
+		// Payday moved to the Friday before a weekend
+		incomeCase{"semimonthly shifted by weekends", []time.Time{
+			time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 2, 14, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		}, CadenceSemimonthly, 400000},
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := testTransactions("acc_1", "ACME CORP PAYROLL PPD", "2000.00", tt.dates)
+			now := tt.dates[len(tt.dates)-1].AddDate(0, 0, 1)
+
+			report := AnalyzeIncome(accounts, transactions, &IncomeOptions{Now: now})
+			if len(report.Streams) != 1 {
+				t.Fatalf("AnalyzeIncome found %d streams, want 1", len(report.Streams))
+			}
+
+			stream := report.Streams[0]
+			if stream.Frequency != tt.want || stream.MonthlyAmount != tt.monthly {
+				t.Errorf("Frequency, MonthlyAmount = %s, %s, want %s, %s", stream.Frequency, stream.MonthlyAmount, tt.want, tt.monthly)
+			}
+			if !stream.Active || !stream.Payroll || stream.Employer != "Acme" {
+				t.Errorf("Active, Payroll, Employer = %v, %v, %q, want an active payroll stream from Acme", stream.Active, stream.Payroll, stream.Employer)
+			}
+			if report.MonthlyIncome != tt.monthly {
+				t.Errorf("MonthlyIncome = %s, want %s", report.MonthlyIncome, tt.monthly)
+			}
+		})
+	}
+}
+
+func TestAnalyzeIncomeIgnores(t *testing.T) {
+	accounts := []TellerAccount{
+		{ID: "checking", Type: TellerAccountTypeDepository},
+		{ID: "card", Type: TellerAccountTypeCredit},
+	}
+	start := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		transactions []TellerTransaction
+	}{
+		{"credit account", testTransactions("card", "ACME CORP PAYROLL", "2000.00", everyDays(start, 14, 6))},
+		{"transfers", testTransactions("checking", "ONLINE TRANSFER FROM SAVINGS", "500.00", everyDays(start, 14, 6))},
+		{"debits", testTransactions("checking", "ACME CORP PAYROLL", "-2000.00", everyDays(start, 14, 6))},
+		{"irregular deposits", testTransactions("checking", "ETSY SALE", "35.00", []time.Time{
+			start, start.AddDate(0, 0, 3), start.AddDate(0, 0, 40), start.AddDate(0, 0, 41),
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := AnalyzeIncome(accounts, tt.transactions, &IncomeOptions{Now: start.AddDate(0, 3, 0)})
+			if len(report.Streams) != 0 {
+				t.Errorf("AnalyzeIncome found %+v, want no stream", report.Streams)
+			}
+		})
+	}
+}
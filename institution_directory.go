@@ -0,0 +1,356 @@
+package teller
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// institutionListMaxAge is how long the client keeps the institution list used by CanUse
+const institutionListMaxAge = 24 * time.Hour
+
+// InstitutionSearchOptions narrows InstitutionDirectory.Search
+type InstitutionSearchOptions struct {
+	// Products only keeps institutions supporting all of them
+	Products []Product
+	// Limit caps the number of results, 0 means no limit
+	Limit int
+}
+
+// InstitutionDirectory keeps the institution list in memory for lookups,
+// search and filtering, downloading it again once it is older than maxAge
+type InstitutionDirectory struct {
+	client *Client
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu           sync.RWMutex
+	institutions []TellerInstitution
+	byID         map[string]int
+	fetchedAt    time.Time
+	refreshing   *directoryRefresh // download in progress, shared by concurrent callers
+}
+
+// directoryRefresh is a download of the institution list; done is closed once err is set
+type directoryRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// NewInstitutionDirectory creates a directory backed by client. A zero maxAge never expires the list.
+func NewInstitutionDirectory(client *Client, maxAge time.Duration) *InstitutionDirectory {
+	return &InstitutionDirectory{client: client, maxAge: maxAge, now: time.Now}
+}
+
+// Refresh downloads the institution list
+func (d *InstitutionDirectory) Refresh() error {
+	return d.RefreshContext(context.Background())
+}
+
+// RefreshContext downloads the institution list. Callers arriving while a
+// download is in progress wait for it instead of starting another one; the
+// download is bounded by the context of the caller that started it.
+func (d *InstitutionDirectory) RefreshContext(ctx context.Context) error {
+	d.mu.Lock()
+	refresh := d.refreshing
+	if refresh == nil {
+		refresh = &directoryRefresh{done: make(chan struct{})}
+		d.refreshing = refresh
+		go d.download(ctx, refresh)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-refresh.done:
+		return refresh.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// download fetches the list for refresh and stores it
+func (d *InstitutionDirectory) download(ctx context.Context, refresh *directoryRefresh) {
+	institutions, err := d.client.Institutions.ListContext(ctx)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer close(refresh.done)
+
+	d.refreshing = nil
+	if err != nil {
+		refresh.err = err
+		return
+	}
+
+	byID := make(map[string]int, len(institutions))
+	for i, institution := range institutions {
+		byID[institution.ID] = i
+	}
+
+	d.institutions = institutions
+	d.byID = byID
+	d.fetchedAt = d.now()
+}
+
+// RefreshEvery refreshes the list every interval until ctx ends.
+// Failed refreshes keep the previous list and are reported to onError, which may be nil.
+func (d *InstitutionDirectory) RefreshEvery(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.RefreshContext(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// load returns the current list, downloading it first if it is missing or stale
+func (d *InstitutionDirectory) load() ([]TellerInstitution, map[string]int, error) {
+	d.mu.RLock()
+	fresh := d.institutions != nil && (d.maxAge <= 0 || d.now().Sub(d.fetchedAt) < d.maxAge)
+	institutions, byID := d.institutions, d.byID
+	d.mu.RUnlock()
+
+	if fresh {
+		return institutions, byID, nil
+	}
+
+	if err := d.Refresh(); err != nil {
+		// Serve a stale list rather than nothing
+		if institutions != nil {
+			return institutions, byID, nil
+		}
+		return nil, nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.institutions, d.byID, nil
+}
+
+// All returns every institution
+func (d *InstitutionDirectory) All() ([]TellerInstitution, error) {
+	institutions, _, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Clone(institutions), nil
+}
+
+// Get returns the institution with the given ID, or ErrNotFound
+func (d *InstitutionDirectory) Get(id string) (*TellerInstitution, error) {
+	institutions, byID, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	i, ok := byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	institution := institutions[i]
+	return &institution, nil
+}
+
+// WithProducts returns the institutions supporting all of the given products
+func (d *InstitutionDirectory) WithProducts(products ...Product) ([]TellerInstitution, error) {
+	return d.Search("", &InstitutionSearchOptions{Products: products})
+}
+
+// Search returns institutions whose name matches query, best matches first.
+// Matching ignores case and punctuation and tolerates small typos.
+// An empty query matches every institution, sorted by name.
+func (d *InstitutionDirectory) Search(query string, options *InstitutionSearchOptions) ([]TellerInstitution, error) {
+	institutions, _, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if options == nil {
+		options = &InstitutionSearchOptions{}
+	}
+
+	type match struct {
+		institution TellerInstitution
+		score       int
+	}
+
+	q := normalizeName(query)
+	var matches []match
+	for _, institution := range institutions {
+		if !hasProducts(institution, options.Products) {
+			continue
+		}
+
+		score := 1
+		if q != "" {
+			score = matchScore(q, normalizeName(institution.Name))
+		}
+		if score > 0 {
+			matches = append(matches, match{institution: institution, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].institution.Name < matches[j].institution.Name
+	})
+
+	if options.Limit > 0 && len(matches) > options.Limit {
+		matches = matches[:options.Limit]
+	}
+
+	result := make([]TellerInstitution, len(matches))
+	for i, m := range matches {
+		result[i] = m.institution
+	}
+
+	return result, nil
+}
+
+func hasProducts(institution TellerInstitution, products []Product) bool {
+	for _, product := range products {
+		if !institution.Supports(product) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeName lowercases s and reduces it to words separated by single spaces
+func normalizeName(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// matchScore rates how well a normalized query matches a normalized name, 0 meaning no match
+func matchScore(query, name string) int {
+	switch {
+	case name == query:
+		return 100
+	case strings.HasPrefix(name, query):
+		return 90
+	}
+
+	nameWords := strings.Fields(name)
+	queryWords := strings.Fields(query)
+
+	for _, word := range nameWords {
+		if strings.HasPrefix(word, query) {
+			return 80
+		}
+	}
+
+	if strings.Contains(name, query) {
+		return 70
+	}
+
+	if allWordsMatch(queryWords, nameWords, strings.HasPrefix) {
+		return 60
+	}
+
+	if isSubsequence(strings.ReplaceAll(query, " ", ""), strings.ReplaceAll(name, " ", "")) && len(query) >= 3 {
+		return 40
+	}
+
+	if allWordsMatch(queryWords, nameWords, func(word, queryWord string) bool {
+		return editDistance(word, queryWord) <= maxTypos(queryWord)
+	}) {
+		return 30
+	}
+
+	return 0
+}
+
+// allWordsMatch reports whether every query word matches some name word
+func allWordsMatch(queryWords, nameWords []string, matches func(word, queryWord string) bool) bool {
+	for _, queryWord := range queryWords {
+		if !slices.ContainsFunc(nameWords, func(word string) bool { return matches(word, queryWord) }) {
+			return false
+		}
+	}
+	return len(queryWords) > 0
+}
+
+func maxTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func isSubsequence(sub, s string) bool {
+	i := 0
+	subRunes := []rune(sub)
+	for _, r := range s {
+		if i < len(subRunes) && subRunes[i] == r {
+			i++
+		}
+	}
+	return i == len(subRunes)
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and adjacent transpositions cost 1
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return rows[len(ra)][len(rb)]
+}
+
+// CanUse reports whether the institution of an account supports a product,
+// so that unsupported calls can be skipped. The institution list is
+// downloaded on first use and kept for a day.
+func (c *Client) CanUse(account TellerAccount, product Product) (bool, error) {
+	institution, err := c.directory.Get(account.Institution.ID)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return institution.Supports(product), nil
+}
@@ -0,0 +1,71 @@
+package teller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client sending its requests to server
+func newTestClient(server *httptest.Server) *Client {
+	c := &Client{baseURL: server.URL, httpClient: server.Client(), seen: &institutionIndex{}}
+	c.chain = c.buildChain()
+	c.initModules()
+	c.directory = NewInstitutionDirectory(c, institutionListMaxAge)
+	return c
+}
+
+func TestInstitutionDirectorySharedRefresh(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Write([]byte(`[{"id":"chase","name":"Chase","products":["balance","transactions"]}]`))
+	}))
+	defer server.Close()
+
+	directory := NewInstitutionDirectory(newTestClient(server), time.Hour)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Go(func() {
+			_, errs[i] = directory.Get("chase")
+		})
+	}
+
+	// Let every reader find the list missing before the download completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Get %d: %v", i, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("institution list downloaded %d times, want 1", n)
+	}
+}
+
+func TestInstitutionDirectoryRefreshContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	directory := NewInstitutionDirectory(newTestClient(server), time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := directory.RefreshContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RefreshContext error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -1,15 +1,67 @@
 package teller
 
 import (
+	"context"
 	"encoding/json"
-	"net/http"
+	"fmt"
+	"slices"
 )
 
+type Product string
+
+const (
+	ProductVerify       Product = "verify"
+	ProductBalance      Product = "balance"
+	ProductTransactions Product = "transactions"
+	ProductIdentity     Product = "identity"
+	ProductPayments     Product = "payments"
+)
+
+// IsKnown reports whether p is a product this version of the library knows.
+// Teller may add values, which decode without error but are not known.
+func (p Product) IsKnown() bool {
+	switch p {
+	case ProductVerify, ProductBalance, ProductTransactions, ProductIdentity, ProductPayments:
+		return true
+	}
+	return false
+}
+
 // TellerInstitution represents a financial institution
 type TellerInstitution struct {
-	ID       string   `json:"id"`
-	Name     string   `json:"name"`
-	Products []string `json:"products"`
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Products []Product `json:"products"`
+	// Extra holds institution fields added to the API after this version of the library
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Supports reports whether the institution offers a product
+func (i TellerInstitution) Supports(product Product) bool {
+	return slices.Contains(i.Products, product)
+}
+
+// UnmarshalJSON decodes the institution, keeping unknown fields in Extra
+func (i *TellerInstitution) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, i)
+}
+
+// MarshalJSON encodes the institution with the unknown fields kept in Extra
+func (i TellerInstitution) MarshalJSON() ([]byte, error) {
+	return marshalResource(i)
+}
+
+// Validate returns a *SchemaError if the institution has unknown fields or enum values
+func (i TellerInstitution) Validate() error {
+	return checkSchema("", i)
+}
+
+func (i TellerInstitution) schemaProblems(path string) []string {
+	problems := unknownFieldProblems(path, i.Extra)
+	for j, product := range i.Products {
+		problems = append(problems, unknownValueProblem(fmt.Sprintf("%s[%d]", fieldPath(path, "products"), j), product, product.IsKnown())...)
+	}
+	return problems
 }
 
 // InstitutionsModule handles institution-related API calls
@@ -19,19 +71,13 @@ type InstitutionsModule struct {
 
 // List retrieves all institutions
 func (m *InstitutionsModule) List() ([]TellerInstitution, error) {
-	req, err := http.NewRequest("GET", m.client.baseURL+"/institutions", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return m.ListContext(context.Background())
+}
 
+// ListContext is List with a context, which cancels the request and any wait for the rate limiter
+func (m *InstitutionsModule) ListContext(ctx context.Context) ([]TellerInstitution, error) {
 	var result []TellerInstitution
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := m.client.send(ctx, "GET", EndpointInstitutions, "/institutions", "", &result); err != nil {
 		return nil, err
 	}
 
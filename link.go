@@ -0,0 +1,172 @@
+package teller
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrMissingLink is returned when following a link the resource does not have
+	ErrMissingLink = errors.New("resource has no such link")
+	// ErrForeignLink is returned when a link does not point at the client's base URL,
+	// so that the access token is never sent anywhere else
+	ErrForeignLink = errors.New("link does not point at the API base URL")
+)
+
+// Follow sends a GET request to a link of a returned resource, such as
+// TellerAccount.Links.Balances, and decodes the response into into.
+// The link is used verbatim, but must point at the client's base URL.
+func (c *Client) Follow(ctx context.Context, link string, into any) error {
+	path, err := c.linkPath(link)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, "GET", endpointForPath(path), path, "", into)
+}
+
+// linkPath validates a link against the base URL and returns its path and query relative to it
+func (c *Client) linkPath(link string) (string, error) {
+	if link == "" {
+		return "", ErrMissingLink
+	}
+
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+
+	if target.Scheme != base.Scheme || !strings.EqualFold(target.Host, base.Host) || target.User != nil {
+		return "", ErrForeignLink
+	}
+
+	basePath := strings.TrimSuffix(base.EscapedPath(), "/")
+	path, ok := strings.CutPrefix(target.EscapedPath(), basePath)
+	if !ok || !strings.HasPrefix(path, "/") {
+		return "", ErrForeignLink
+	}
+
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+
+	return path, nil
+}
+
+// endpointForPath returns the endpoint template matching a path, or "" for unknown paths
+func endpointForPath(path string) Endpoint {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case len(segments) == 1 && segments[0] == "identity":
+		return EndpointIdentity
+	case len(segments) == 1 && segments[0] == "institutions":
+		return EndpointInstitutions
+	case segments[0] != "accounts":
+		return ""
+	}
+
+	switch len(segments) {
+	case 1:
+		return EndpointAccounts
+	case 2:
+		return EndpointAccount
+	case 3:
+		switch segments[2] {
+		case "details":
+			return EndpointAccountDetails
+		case "balances":
+			return EndpointAccountBalances
+		case "transactions":
+			return EndpointTransactions
+		}
+	case 4:
+		if segments[2] == "transactions" {
+			return EndpointTransaction
+		}
+	}
+
+	return ""
+}
+
+// Fetch retrieves the account again from its self link
+func (a TellerAccount) Fetch(ctx context.Context, client *Client) (*TellerAccount, error) {
+	var result TellerAccount
+	if err := client.Follow(ctx, a.Links.Self, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// FetchDetails retrieves the account's details from its details link
+func (a TellerAccount) FetchDetails(ctx context.Context, client *Client) (*TellerAccountDetails, error) {
+	var result TellerAccountDetails
+	if err := client.Follow(ctx, a.Links.Details, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// FetchBalances retrieves the account's balances from its balances link
+func (a TellerAccount) FetchBalances(ctx context.Context, client *Client) (*TellerAccountBalances, error) {
+	var result TellerAccountBalances
+	if err := client.Follow(ctx, a.Links.Balances, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// FetchTransactions retrieves the account's transactions from its transactions link
+func (a TellerAccount) FetchTransactions(ctx context.Context, client *Client) ([]TellerTransaction, error) {
+	var result []TellerTransaction
+	if err := client.Follow(ctx, a.Links.Transactions, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FetchAccount retrieves the account the details belong to
+func (d TellerAccountDetails) FetchAccount(ctx context.Context, client *Client) (*TellerAccount, error) {
+	return fetchAccount(ctx, client, d.Links.Account)
+}
+
+// FetchAccount retrieves the account the balances belong to
+func (b TellerAccountBalances) FetchAccount(ctx context.Context, client *Client) (*TellerAccount, error) {
+	return fetchAccount(ctx, client, b.Links.Account)
+}
+
+// Fetch retrieves the transaction again from its self link, e.g. to see whether it posted
+func (t TellerTransaction) Fetch(ctx context.Context, client *Client) (*TellerTransaction, error) {
+	var result TellerTransaction
+	if err := client.Follow(ctx, t.Links.Self, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// FetchAccount retrieves the account of the transaction
+func (t TellerTransaction) FetchAccount(ctx context.Context, client *Client) (*TellerAccount, error) {
+	return fetchAccount(ctx, client, t.Links.Account)
+}
+
+func fetchAccount(ctx context.Context, client *Client, link string) (*TellerAccount, error) {
+	var result TellerAccount
+	if err := client.Follow(ctx, link, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
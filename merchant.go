@@ -0,0 +1,344 @@
+package teller
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Merchant is a merchant name cleaned from a raw bank description
+type Merchant struct {
+	// Name is the cleaned, title-cased name, e.g. "Starbucks"
+	Name string
+	// Key identifies the merchant for grouping and search, e.g. "starbucks"
+	Key string
+}
+
+// processorPrefixes start descriptions before the merchant name, longest first
+var processorPrefixes = []string{
+	"PURCHASE AUTHORIZED ON", "DEBIT CARD PURCHASE", "CHECK CARD PURCHASE", "RECURRING DEBIT CARD",
+	"RECURRING PAYMENT", "PREAUTHORIZED DEBIT", "ELECTRONIC PAYMENT", "ONLINE PAYMENT", "POS PURCHASE",
+	"POS DEBIT", "POS WITHDRAWAL", "CARD PURCHASE", "DEBIT PURCHASE", "VISA DDA PUR", "VISA PURCHASE",
+	"DBT CRD", "ACH DEBIT", "ACH CREDIT", "ACH PMT", "CHECKCARD", "CHECK CARD", "PURCHASE", "POS",
+	"DEBIT", "ACH",
+}
+
+// aggregatorPrefixes are payment processors that prefix the merchant name, e.g. "SQ *BLUE BOTTLE"
+var aggregatorPrefixes = []string{"SQ", "TST", "SP", "PAYPAL", "PP", "IN", "PY", "GOOGLE", "APPLE.COM/BILL", "EB", "FS", "DD", "BT"}
+
+// aggregatorNames name the processors behind prefixes, for descriptions
+// where no merchant name follows the prefix, e.g. "SQ *"
+var aggregatorNames = map[string]string{
+	"SQ": "SQUARE", "TST": "TOAST", "PAYPAL": "PAYPAL", "PP": "PAYPAL", "GOOGLE": "GOOGLE", "APPLE.COM/BILL": "APPLE",
+}
+
+// defaultMerchantAliases maps the keys of abbreviated names to their usual name
+var defaultMerchantAliases = map[string]string{
+	"amzn":               "Amazon",
+	"amzn mktp":          "Amazon",
+	"amzn mktp us":       "Amazon",
+	"amazon mktplace":    "Amazon",
+	"amazon prime":       "Amazon Prime",
+	"amzn prime":         "Amazon Prime",
+	"wal mart":           "Walmart",
+	"wm supercenter":     "Walmart",
+	"walmart com":        "Walmart",
+	"mcdonald s":         "McDonald's",
+	"mcdonalds":          "McDonald's",
+	"wholefds":           "Whole Foods",
+	"wholefds mkt":       "Whole Foods",
+	"whole foods mkt":    "Whole Foods",
+	"whole foods market": "Whole Foods",
+	"uber trip":          "Uber",
+	"uber eats":          "Uber Eats",
+	"lyft ride":          "Lyft",
+	"netflix com":        "Netflix",
+	"spotify usa":        "Spotify",
+	"apple com bill":     "Apple",
+	"7 eleven":           "7-Eleven",
+	"cvs pharmacy":       "CVS",
+	"trader joe s":       "Trader Joe's",
+	"paypal":             "PayPal",
+}
+
+var (
+	datePattern      = regexp.MustCompile(`\b\d{1,2}/\d{1,2}(/\d{2,4})?\b`)
+	cardPattern      = regexp.MustCompile(`\b(CARD|CRD)\s*(ENDING\s*(IN)?\s*)?[X*#]*\d{4}\b|\b[X*]{2,}\d{2,4}\b`)
+	phonePattern     = regexp.MustCompile(`\b\d{3}[-. ]\d{3}[-. ]\d{4}\b`)
+	referencePattern = regexp.MustCompile(`\b(REF|ID|TRACE|CONF|TXN)\s*#?\s*[A-Z0-9]*\d[A-Z0-9]*\b`)
+	domainPattern    = regexp.MustCompile(`\.(COM|NET|ORG|IO|CO)\b.*$`)
+)
+
+var usStateCodes = func() map[string]bool {
+	codes := map[string]bool{}
+	for _, code := range usStates {
+		codes[code] = true
+	}
+	return codes
+}()
+
+// knownCities are cities common in descriptions. They are stripped even
+// when a single word is left, unlike other words before a state code.
+var knownCities = func() map[string]bool {
+	cities := map[string]bool{}
+	for _, city := range []string{
+		"ATLANTA", "AUSTIN", "BALTIMORE", "BOSTON", "BROOKLYN", "CHARLOTTE", "CHICAGO", "CLEVELAND",
+		"COLUMBUS", "DALLAS", "DENVER", "DETROIT", "HONOLULU", "HOUSTON", "INDIANAPOLIS", "JACKSONVILLE",
+		"LAS VEGAS", "LOS ANGELES", "LOS GATOS", "MEMPHIS", "MIAMI", "MILWAUKEE", "MINNEAPOLIS",
+		"NASHVILLE", "NEW ORLEANS", "NEW YORK", "OAKLAND", "OMAHA", "ORLANDO", "PHILADELPHIA", "PHOENIX",
+		"PITTSBURGH", "PORTLAND", "RALEIGH", "SACRAMENTO", "SALT LAKE CITY", "SAN ANTONIO", "SAN DIEGO",
+		"SAN FRANCISCO", "SAN JOSE", "SEATTLE", "ST LOUIS", "TAMPA", "WASHINGTON",
+	} {
+		cities[city] = true
+	}
+	return cities
+}()
+
+// cityPrefixes start multi-word city names, e.g. SAN FRANCISCO
+var cityPrefixes = map[string]bool{
+	"SAN": true, "SANTA": true, "LOS": true, "LAS": true, "NEW": true, "SAINT": true, "ST": true,
+	"FORT": true, "FT": true, "EL": true, "PALO": true, "SALT": true, "LAKE": true, "PORT": true,
+	"NORTH": true, "SOUTH": true, "EAST": true, "WEST": true, "MOUNT": true, "MT": true,
+}
+
+// MerchantNormalizer cleans raw bank descriptions into merchant names. It
+// strips processor prefixes, dates, card fragments, store numbers and
+// trailing locations, then applies aliases. It is safe for concurrent use.
+type MerchantNormalizer struct {
+	mu      sync.RWMutex
+	aliases map[string]string
+}
+
+// NewMerchantNormalizer creates a normalizer with aliases for common abbreviated merchants
+func NewMerchantNormalizer() *MerchantNormalizer {
+	aliases := make(map[string]string, len(defaultMerchantAliases))
+	for key, name := range defaultMerchantAliases {
+		aliases[key] = name
+	}
+	return &MerchantNormalizer{aliases: aliases}
+}
+
+var defaultMerchantNormalizer = NewMerchantNormalizer()
+
+// NormalizeMerchant cleans a raw description with the default normalizer
+func NormalizeMerchant(raw string) Merchant {
+	return defaultMerchantNormalizer.Normalize(raw)
+}
+
+// MerchantKey reduces a merchant name to lowercase words without punctuation,
+// e.g. "Trader Joe's" gives "trader joe s"
+func MerchantKey(name string) string {
+	return normalizeName(foldAccents(strings.ToLower(name)))
+}
+
+// Alias makes cleaned names with the key of from normalize to name
+func (n *MerchantNormalizer) Alias(from, name string) *MerchantNormalizer {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.aliases[MerchantKey(from)] = name
+	return n
+}
+
+// Transaction returns the merchant of a transaction, from its counterparty name when Teller provides one
+func (n *MerchantNormalizer) Transaction(transaction TellerTransaction) Merchant {
+	return n.Normalize(transactionMerchant(transaction))
+}
+
+// transactionMerchant returns the counterparty name of a transaction, or its description
+func transactionMerchant(transaction TellerTransaction) string {
+	if name := transaction.Details.Counterparty.Name; name != nil && *name != "" {
+		return *name
+	}
+	return transaction.Description
+}
+
+// Normalize cleans a raw description, e.g. "POS DEBIT 1234 STARBUCKS #5521
+// SEATTLE WA" gives Starbucks
+func (n *MerchantNormalizer) Normalize(raw string) Merchant {
+	name := cleanMerchantName(raw)
+	if name == "" {
+		// Nothing but processor words, as in "ACH": keep the description
+		name = strings.Join(strings.Fields(raw), " ")
+		return Merchant{Name: name, Key: MerchantKey(name)}
+	}
+	key := MerchantKey(name)
+
+	n.mu.RLock()
+	alias, ok := n.aliases[key]
+	n.mu.RUnlock()
+
+	if ok {
+		return Merchant{Name: alias, Key: MerchantKey(alias)}
+	}
+
+	return Merchant{Name: titleCase(name), Key: key}
+}
+
+func cleanMerchantName(raw string) string {
+	s := " " + strings.ToUpper(strings.Join(strings.Fields(raw), " ")) + " "
+
+	s = datePattern.ReplaceAllString(s, " ")
+	s = cardPattern.ReplaceAllString(s, " ")
+	// A phone number ends the name like a store number, the location follows it
+	if loc := phonePattern.FindStringIndex(s); loc != nil && strings.TrimSpace(s[:loc[0]]) != "" {
+		s = s[:loc[0]]
+	}
+	s = phonePattern.ReplaceAllString(s, " ")
+	s = referencePattern.ReplaceAllString(s, " ")
+	s = strings.TrimSpace(s)
+
+	s = trimProcessorPrefixes(s)
+	s = trimAggregatorPrefix(s)
+
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '*' || r == ','
+	})
+
+	// Store numbers, reference codes and URLs end the name, the location follows them
+	for i, word := range words {
+		if i > 0 && (isStoreNumber(word) || isReferenceCode(word) || strings.ContainsAny(word, "./") && len(word) > 1) {
+			words = words[:i]
+			break
+		}
+		if i > 0 && word == "STORE" {
+			words = words[:i]
+			break
+		}
+	}
+
+	if len(words) > 0 {
+		words[0] = domainPattern.ReplaceAllString(words[0], "")
+	}
+
+	words = trimLocation(words)
+
+	for len(words) > 1 && isBusinessSuffix(words) {
+		words = words[:len(words)-1]
+	}
+
+	var kept []string
+	for _, word := range words {
+		if word = strings.Trim(word, ".-#"); word != "" {
+			kept = append(kept, word)
+		}
+	}
+
+	return strings.Join(kept, " ")
+}
+
+// businessSuffixes are legal forms dropped from the end of names
+var businessSuffixes = map[string]bool{"INC": true, "LLC": true, "LTD": true, "CORP": true, "CO": true}
+
+// isBusinessSuffix reports whether the last word is a legal form. CO is
+// also a state code, so it is kept when it would leave a single word, as
+// in "TACO CO".
+func isBusinessSuffix(words []string) bool {
+	suffix := strings.Trim(words[len(words)-1], ".")
+	return businessSuffixes[suffix] && (len(words) > 2 || !usStateCodes[suffix])
+}
+
+// isStoreNumber reports whether word is a store or terminal number such as "#5521" or "10234"
+func isStoreNumber(word string) bool {
+	digits := strings.TrimPrefix(word, "#")
+	return len(digits) >= 2 && strings.Trim(digits, "0123456789-") == "" && (digits != word || len(digits) >= 3)
+}
+
+// trimProcessorPrefixes removes card processing prefixes and the digits that follow them
+func trimProcessorPrefixes(s string) string {
+	for {
+		trimmed := s
+		for _, prefix := range processorPrefixes {
+			if rest, ok := strings.CutPrefix(trimmed, prefix); ok && (rest == "" || !isWordRune(rest[0])) {
+				trimmed = strings.TrimLeft(rest, " -:0123456789")
+				break
+			}
+		}
+		if trimmed == s {
+			return s
+		}
+		s = trimmed
+	}
+}
+
+func trimAggregatorPrefix(s string) string {
+	for _, prefix := range aggregatorPrefixes {
+		rest, ok := strings.CutPrefix(s, prefix)
+		if !ok {
+			continue
+		}
+		rest = strings.TrimLeft(rest, " ")
+		after, ok := strings.CutPrefix(rest, "*")
+		if !ok {
+			continue
+		}
+		if after = strings.TrimSpace(after); after != "" {
+			return after
+		}
+		if name, ok := aggregatorNames[prefix]; ok {
+			return name
+		}
+	}
+	return s
+}
+
+// trimLocation removes a trailing state, the city before it and a country
+// following them. A state code or country right after the name is kept, as
+// in "DUNKIN ME" or "TOYS R US". A city is only guessed when two words of
+// the name are left, so "BEST BUY MN" keeps BUY; known cities may leave one,
+// as in "STARBUCKS SEATTLE WA".
+func trimLocation(words []string) []string {
+	if n := len(words); n >= 4 && (words[n-1] == "US" || words[n-1] == "USA") && usStateCodes[words[n-2]] {
+		words = words[:n-1]
+	}
+
+	n := len(words)
+	if n < 3 || !usStateCodes[words[n-1]] {
+		return words
+	}
+	words = words[:n-1]
+
+	for size := 3; size >= 1; size-- {
+		if start := len(words) - size; start >= 1 && knownCities[strings.Join(words[start:], " ")] {
+			return words[:start]
+		}
+	}
+
+	start := len(words) - 1
+	for start > 0 && cityPrefixes[words[start-1]] {
+		start--
+	}
+	if start >= 2 {
+		return words[:start]
+	}
+	return words
+}
+
+func isWordRune(b byte) bool {
+	return b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// isReferenceCode reports whether word mixes letters and at least three digits, like "S461015572"
+func isReferenceCode(word string) bool {
+	digits, letters := 0, 0
+	for _, r := range word {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case unicode.IsLetter(r):
+			letters++
+		}
+	}
+	return digits >= 3 && letters > 0
+}
+
+func titleCase(s string) string {
+	words := strings.Fields(strings.ToLower(s))
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,71 @@
+package teller
+
+import "testing"
+
+func TestNormalizeMerchant(t *testing.T) {
+	tests := []struct {
+		raw  string
+		name string
+		key  string
+	}{
+		// Processor prefixes, store numbers and locations
+		{"POS DEBIT 1234 STARBUCKS #5521 SEATTLE WA", "Starbucks", "starbucks"},
+		{"STARBUCKS STORE 00123 SEATTLE WA", "Starbucks", "starbucks"},
+		{"STARBUCKS SEATTLE WA USA", "Starbucks", "starbucks"},
+		{"CHECKCARD 0512 SHELL OIL 57444 HOUSTON TX", "Shell Oil", "shell oil"},
+		{"DEBIT CARD PURCHASE XXXXX1234 TARGET T-2345 MINNEAPOLIS MN", "Target", "target"},
+		{"PURCHASE AUTHORIZED ON 05/12 UBER *TRIP HELP.UBER.COM CA S461015572 CARD 1234", "Uber", "uber"},
+		{"ACH DEBIT COMCAST CABLE COMM 8773450", "Comcast Cable Comm", "comcast cable comm"},
+
+		// Aggregators
+		{"SQ *BLUE BOTTLE COFFEE San Francisco CA", "Blue Bottle Coffee", "blue bottle coffee"},
+		{"TST* SWEETGREEN NEW YORK NY", "Sweetgreen", "sweetgreen"},
+		{"PAYPAL *JOHNDOE 402-935-7733 CA", "Johndoe", "johndoe"},
+		{"GOOGLE *YouTube Premium g.co/helppay# CA", "Youtube Premium", "youtube premium"},
+
+		// Domains and aliases
+		{"AMZN MKTP US*2K4LM0PQ1 AMZN.COM/BILL WA", "Amazon", "amazon"},
+		{"Amazon.com*MK1AB2CD3", "Amazon", "amazon"},
+		{"NETFLIX.COM LOS GATOS CA", "Netflix", "netflix"},
+		{"APPLE.COM/BILL 866-712-7753 CA", "Apple", "apple"},
+		{"SPOTIFY USA", "Spotify", "spotify"},
+		{"WAL-MART #1234 BENTONVILLE AR", "Walmart", "walmart"},
+		{"MCDONALD'S F12345 CHICAGO IL", "McDonald's", "mcdonald s"},
+		{"WHOLEFDS MKT 10234 AUSTIN TX", "Whole Foods", "whole foods"},
+		{"TRADER JOE'S #552 QPS PORTLAND OR", "Trader Joe's", "trader joe s"},
+		{"7-ELEVEN 34567 DALLAS TX", "7-Eleven", "7 eleven"},
+		{"CVS/PHARMACY #04567 BOSTON MA", "CVS", "cvs"},
+
+		// Names ending in a state code or country are not locations
+		{"TOYS R US", "Toys R Us", "toys r us"},
+		{"TOYS R US #8812 NEW YORK NY", "Toys R Us", "toys r us"},
+		{"DUNKIN ME", "Dunkin Me", "dunkin me"},
+		{"SPEEDY OK", "Speedy Ok", "speedy ok"},
+		{"TACO CO", "Taco Co", "taco co"},
+
+		// A guessed city never leaves a single word
+		{"BEST BUY MN", "Best Buy", "best buy"},
+		{"PANERA BREAD MADISON WI", "Panera Bread", "panera bread"},
+		{"CHIPOTLE BOULDER CO", "Chipotle Boulder", "chipotle boulder"},
+
+		// Descriptions without a merchant name
+		{"SQ *", "Square", "square"},
+		{"PAYPAL *", "PayPal", "paypal"},
+		{"ACH", "ACH", "ach"},
+		{"", "", ""},
+
+		// Legal forms and accents
+		{"BLUE APRON LLC", "Blue Apron", "blue apron"},
+		{"ACME WIDGETS INC", "Acme Widgets", "acme widgets"},
+		{"Café Luna", "Café Luna", "cafe luna"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got := NormalizeMerchant(tt.raw)
+			if got.Name != tt.name || got.Key != tt.key {
+				t.Errorf("NormalizeMerchant(%q) = {%q, %q}, want {%q, %q}", tt.raw, got.Name, got.Key, tt.name, tt.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package teller
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRateLimitRetries is how many times a request answered with
+// 429 Too Many Requests is retried before the error is returned
+const maxRateLimitRetries = 3
+
+// Doer sends an HTTP request, *http.Client implements it
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to the Doer interface
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the Doer sending the client's requests. The request
+// context carries a RequestInfo with the endpoint template and the identity
+// of the access token, see RequestInfoFromContext.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware adds middlewares to the client. Middlewares run in the order
+// they are registered: the first one sees the request first and the response
+// last. All registered middlewares wrap the built-in retry, logging and rate
+// limiting middlewares, which run in that order closest to the transport.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+func (c *Client) buildChain() Doer {
+	chain := append([]Middleware{}, c.middlewares...)
+	chain = append(chain, RetryMiddleware(maxRateLimitRetries))
+	if c.logger != nil {
+		chain = append(chain, LoggingMiddleware(c.logger))
+	}
+	if c.limiter != nil {
+		chain = append(chain, RateLimitMiddleware(c.limiter))
+	}
+
+	var doer Doer = c.httpClient
+	for i := len(chain) - 1; i >= 0; i-- {
+		doer = chain[i](doer)
+	}
+
+	return doer
+}
+
+func (c *Client) doer() Doer {
+	if c.chain == nil {
+		return c.buildChain()
+	}
+	return c.chain
+}
+
+// RetryMiddleware retries requests answered with 429 Too Many Requests up to
+// maxRetries times, waiting as long as Retry-After says or backing off
+// exponentially. Each retry carries an incremented RequestInfo.Attempt.
+func RetryMiddleware(maxRetries int) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			info, _ := RequestInfoFromContext(ctx)
+
+			for retry := 0; ; retry++ {
+				resp, err := next.Do(req)
+				if err != nil || resp.StatusCode != http.StatusTooManyRequests || retry >= maxRetries {
+					return resp, err
+				}
+
+				wait := retryAfter(resp.Header.Get("Retry-After"), retry)
+				resp.Body.Close()
+
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				case <-timer.C:
+				}
+
+				info.Attempt = retry + 2
+				req = req.Clone(contextWithRequestInfo(ctx, info))
+			}
+		})
+	}
+}
+
+// LoggingMiddleware logs every request and response. Access tokens are logged
+// by fingerprint and paths as endpoint templates, so no tokens, account IDs or
+// account numbers are written.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			info, _ := RequestInfoFromContext(ctx)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("endpoint", info.Endpoint),
+				slog.Int("attempt", info.Attempt),
+			}
+			if info.TokenFingerprint != "" {
+				attrs = append(attrs, slog.String("token", info.TokenFingerprint))
+			}
+
+			logger.LogAttrs(ctx, slog.LevelDebug, "teller request", attrs...)
+
+			start := time.Now()
+			resp, err := next.Do(req)
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "teller request failed", append(attrs, slog.Any("error", err))...)
+				return nil, err
+			}
+
+			attrs = append(attrs,
+				slog.Int("status", resp.StatusCode),
+				slog.String("request_id", resp.Header.Get(requestIDHeader)),
+			)
+
+			level := slog.LevelInfo
+			if resp.StatusCode >= 400 {
+				level = slog.LevelWarn
+			}
+			logger.LogAttrs(ctx, level, "teller response", attrs...)
+
+			return resp, nil
+		})
+	}
+}
+
+// RateLimitMiddleware waits for limiter before sending each request,
+// using the request's basic auth username as the access token
+func RateLimitMiddleware(limiter *RateLimiter) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			token, _, _ := req.BasicAuth()
+			if err := limiter.Wait(req.Context(), token); err != nil {
+				return nil, err
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// retryAfter parses a Retry-After header, falling back to exponential backoff
+func retryAfter(header string, retry int) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return time.Duration(1<<retry) * time.Second
+}
@@ -0,0 +1,164 @@
+// Package otelteller instruments a teller.Client with OpenTelemetry traces and metrics.
+//
+//	client, err := teller.NewClient(certPath, keyPath, nil, otelteller.WithInstrumentation())
+//
+// Every API call gets a client span named after its endpoint template, e.g.
+// "GET /accounts/{id}/transactions", and is counted in the
+// teller.client.requests, teller.client.request.duration and
+// teller.client.errors metrics.
+package otelteller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/maxint-app/teller-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/maxint-app/teller-go/otelteller"
+
+// unknownEndpoint stands for requests without an endpoint template. Raw
+// paths contain account and transaction IDs and would make attributes unbounded.
+const unknownEndpoint = "unknown"
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option configures the instrumentation
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider, defaults to the global one
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider, defaults to the global one
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithInstrumentation returns a client option that instruments the client's transport
+func WithInstrumentation(options ...Option) teller.ClientOption {
+	return teller.WithTransport(func(base http.RoundTripper) http.RoundTripper {
+		return NewTransport(base, options...)
+	})
+}
+
+// Transport is an http.RoundTripper recording a span and metrics for every request
+type Transport struct {
+	base     http.RoundTripper
+	tracer   trace.Tracer
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+// NewTransport wraps base, defaulting to http.DefaultTransport if nil
+func NewTransport(base http.RoundTripper, options ...Option) *Transport {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	meter := cfg.meterProvider.Meter(instrumentationName)
+
+	// Instrument creation only fails on invalid names, which are constants here
+	requests, _ := meter.Int64Counter("teller.client.requests",
+		metric.WithDescription("Number of Teller API requests"),
+		metric.WithUnit("{request}"),
+	)
+	duration, _ := meter.Float64Histogram("teller.client.request.duration",
+		metric.WithDescription("Duration of Teller API requests"),
+		metric.WithUnit("s"),
+	)
+	errors, _ := meter.Int64Counter("teller.client.errors",
+		metric.WithDescription("Number of failed Teller API requests, including error statuses"),
+		metric.WithUnit("{request}"),
+	)
+
+	return &Transport{
+		base:     base,
+		tracer:   cfg.tracerProvider.Tracer(instrumentationName),
+		requests: requests,
+		duration: duration,
+		errors:   errors,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	info, ok := teller.RequestInfoFromContext(req.Context())
+	if !ok {
+		info = teller.RequestInfo{Method: req.Method, Attempt: 1}
+	}
+	if info.Endpoint == "" {
+		info.Endpoint = unknownEndpoint
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.template", info.Endpoint),
+		attribute.String("server.address", req.URL.Hostname()),
+	}
+	if info.InstitutionID != "" {
+		attrs = append(attrs, attribute.String("teller.institution.id", info.InstitutionID))
+	}
+
+	ctx, span := t.tracer.Start(req.Context(), req.Method+" "+info.Endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(attribute.Int("http.request.resend_count", info.Attempt-1)),
+	)
+	defer span.End()
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	elapsed := time.Since(start).Seconds()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		errAttrs := append(attrs, attribute.String("error.type", "transport"))
+		t.requests.Add(ctx, 1, metric.WithAttributes(errAttrs...))
+		t.duration.Record(ctx, elapsed, metric.WithAttributes(errAttrs...))
+		t.errors.Add(ctx, 1, metric.WithAttributes(errAttrs...))
+		return nil, err
+	}
+
+	attrs = append(attrs, attribute.Int("http.response.status_code", resp.StatusCode))
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if requestID := resp.Header.Get("X-Request-Id"); requestID != "" {
+		span.SetAttributes(attribute.String("teller.request.id", requestID))
+	}
+
+	t.requests.Add(ctx, 1, metric.WithAttributes(attrs...))
+	t.duration.Record(ctx, elapsed, metric.WithAttributes(attrs...))
+
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		errAttrs := append(attrs, attribute.String("error.type", strconv.Itoa(resp.StatusCode)))
+		t.errors.Add(ctx, 1, metric.WithAttributes(errAttrs...))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,255 @@
+// Package promteller exposes Prometheus metrics about a teller.Client and
+// the webhooks it receives.
+//
+//	collector := promteller.NewCollector()
+//	client, err := teller.NewClient(certPath, keyPath, nil, collector.Instrument())
+//	prometheus.MustRegister(collector)
+package promteller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"weak"
+
+	"github.com/maxint-app/teller-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "teller"
+
+// unknownEndpoint labels requests without an endpoint template. Raw paths
+// contain account and transaction IDs and would make label values unbounded.
+const unknownEndpoint = "unknown"
+
+type WebhookFailureReason = string
+
+const (
+	WebhookFailureReasonNoSigningSecrets   WebhookFailureReason = "no_signing_secrets"
+	WebhookFailureReasonMissingSignature   WebhookFailureReason = "missing_signature"
+	WebhookFailureReasonMissingTimestamp   WebhookFailureReason = "missing_timestamp"
+	WebhookFailureReasonNoSignatures       WebhookFailureReason = "no_signatures"
+	WebhookFailureReasonInvalidTimestamp   WebhookFailureReason = "invalid_timestamp"
+	WebhookFailureReasonTimestampTooOld    WebhookFailureReason = "timestamp_too_old"
+	WebhookFailureReasonVerificationFailed WebhookFailureReason = "verification_failed"
+	WebhookFailureReasonInvalidPayload     WebhookFailureReason = "invalid_payload"
+)
+
+var webhookFailureReasons = map[error]WebhookFailureReason{
+	teller.ErrWebhookNoSigningSecrets:   WebhookFailureReasonNoSigningSecrets,
+	teller.ErrWebhookMissingSignature:   WebhookFailureReasonMissingSignature,
+	teller.ErrWebhookMissingTimestamp:   WebhookFailureReasonMissingTimestamp,
+	teller.ErrWebhookNoSignatures:       WebhookFailureReasonNoSignatures,
+	teller.ErrWebhookInvalidTimestamp:   WebhookFailureReasonInvalidTimestamp,
+	teller.ErrWebhookTimestampTooOld:    WebhookFailureReasonTimestampTooOld,
+	teller.ErrWebhookVerificationFailed: WebhookFailureReasonVerificationFailed,
+}
+
+// Collector is a prometheus.Collector tracking API requests, rate limiting,
+// webhook deliveries and certificate expiry
+type Collector struct {
+	requests        *prometheus.CounterVec
+	duration        *prometheus.HistogramVec
+	retries         *prometheus.CounterVec
+	webhooks        *prometheus.CounterVec
+	webhookFailures *prometheus.CounterVec
+
+	throttledDesc     *prometheus.Desc
+	throttledTimeDesc *prometheus.Desc
+	rejectedDesc      *prometheus.Desc
+	certExpiryDesc    *prometheus.Desc
+
+	// clients are held weakly so that instrumenting a short-lived client
+	// does not keep it alive; collected clients are dropped on Collect
+	mu      sync.Mutex
+	clients []weak.Pointer[teller.Client]
+}
+
+// NewCollector creates a collector. Use Instrument to attach it to a client.
+func NewCollector() *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Teller API requests by endpoint template, method and status code.",
+		}, []string{"endpoint", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of Teller API requests by endpoint template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "method"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_retries_total",
+			Help:      "Teller API requests retried after being rate limited, by endpoint template.",
+		}, []string{"endpoint"}),
+		webhooks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_deliveries_total",
+			Help:      "Verified webhook deliveries by event type.",
+		}, []string{"type"}),
+		webhookFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_verification_failures_total",
+			Help:      "Webhook deliveries rejected by ConstructWebhook, by reason.",
+		}, []string{"reason"}),
+		throttledDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rate_limiter", "throttled_total"),
+			"Requests that waited for the client-side rate limiter.", nil, nil,
+		),
+		throttledTimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rate_limiter", "wait_seconds_total"),
+			"Time requests spent waiting for the client-side rate limiter.", nil, nil,
+		),
+		rejectedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rate_limiter", "rejected_total"),
+			"Requests failed because the rate limiter wait would exceed their deadline.", nil, nil,
+		),
+		certExpiryDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "certificate", "days_to_expiry"),
+			"Days until the client certificate expires.", nil, nil,
+		),
+	}
+}
+
+// Instrument returns a client option that reports the client's requests,
+// rate limiter and certificate to the collector. Several clients may be
+// instrumented: their limiter stats are summed, each limiter counted once,
+// and the certificate gauge reports the soonest expiry. A client stops being
+// reported once it is garbage collected.
+func (c *Collector) Instrument() teller.ClientOption {
+	wrap := teller.WithTransport(func(base http.RoundTripper) http.RoundTripper {
+		return &transport{base: base, collector: c}
+	})
+
+	return func(client *teller.Client) {
+		c.mu.Lock()
+		c.clients = append(c.clients, weak.Make(client))
+		c.mu.Unlock()
+
+		wrap(client)
+	}
+}
+
+// ObserveWebhook records the result of teller.ConstructWebhook
+func (c *Collector) ObserveWebhook(event *teller.WebhookEvent, err error) {
+	if err != nil {
+		c.webhookFailures.WithLabelValues(webhookFailureReason(err)).Inc()
+		return
+	}
+
+	c.webhooks.WithLabelValues(event.Type).Inc()
+}
+
+// ConstructWebhook calls teller.ConstructWebhook and records the result
+func (c *Collector) ConstructWebhook(body []byte, signatureHeader string, signingSecrets []string) (*teller.WebhookEvent, error) {
+	event, err := teller.ConstructWebhook(body, signatureHeader, signingSecrets)
+	c.ObserveWebhook(event, err)
+	return event, err
+}
+
+func webhookFailureReason(err error) WebhookFailureReason {
+	for target, reason := range webhookFailureReasons {
+		if errors.Is(err, target) {
+			return reason
+		}
+	}
+	return WebhookFailureReasonInvalidPayload
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.retries.Describe(ch)
+	c.webhooks.Describe(ch)
+	c.webhookFailures.Describe(ch)
+	ch <- c.throttledDesc
+	ch <- c.throttledTimeDesc
+	ch <- c.rejectedDesc
+	ch <- c.certExpiryDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.retries.Collect(ch)
+	c.webhooks.Collect(ch)
+	c.webhookFailures.Collect(ch)
+
+	c.mu.Lock()
+	var clients []*teller.Client
+	live := c.clients[:0]
+	for _, pointer := range c.clients {
+		if client := pointer.Value(); client != nil {
+			clients = append(clients, client)
+			live = append(live, pointer)
+		}
+	}
+	clear(c.clients[len(live):])
+	c.clients = live
+	c.mu.Unlock()
+
+	// Clients may share a limiter, which must only be counted once
+	var stats teller.RateLimiterStats
+	limiters := map[*teller.RateLimiter]bool{}
+	var expiry time.Time
+	for _, client := range clients {
+		if limiter := client.RateLimiter(); limiter != nil && !limiters[limiter] {
+			limiters[limiter] = true
+			s := limiter.Stats()
+			stats.Throttled += s.Throttled
+			stats.ThrottledTime += s.ThrottledTime
+			stats.Rejected += s.Rejected
+		}
+		if e := client.CertificateExpiry(); !e.IsZero() && (expiry.IsZero() || e.Before(expiry)) {
+			expiry = e
+		}
+	}
+
+	if len(limiters) > 0 {
+		ch <- prometheus.MustNewConstMetric(c.throttledDesc, prometheus.CounterValue, float64(stats.Throttled))
+		ch <- prometheus.MustNewConstMetric(c.throttledTimeDesc, prometheus.CounterValue, stats.ThrottledTime.Seconds())
+		ch <- prometheus.MustNewConstMetric(c.rejectedDesc, prometheus.CounterValue, float64(stats.Rejected))
+	}
+
+	if !expiry.IsZero() {
+		days := time.Until(expiry).Hours() / 24
+		ch <- prometheus.MustNewConstMetric(c.certExpiryDesc, prometheus.GaugeValue, days)
+	}
+}
+
+type transport struct {
+	base      http.RoundTripper
+	collector *Collector
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	info, ok := teller.RequestInfoFromContext(req.Context())
+	if !ok {
+		info = teller.RequestInfo{Attempt: 1}
+	}
+	if info.Endpoint == "" {
+		info.Endpoint = unknownEndpoint
+	}
+
+	if info.Attempt > 1 {
+		t.collector.retries.WithLabelValues(info.Endpoint).Inc()
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	t.collector.duration.WithLabelValues(info.Endpoint, req.Method).Observe(time.Since(start).Seconds())
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	t.collector.requests.WithLabelValues(info.Endpoint, req.Method, status).Inc()
+
+	return resp, err
+}
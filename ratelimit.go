@@ -0,0 +1,203 @@
+package teller
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxTokenBuckets is the most per-token buckets kept at once. Past it, full
+// buckets are dropped first, then the least recently used one.
+const maxTokenBuckets = 1024
+
+// ErrRateLimitWait is returned when waiting for the rate limiter would exceed the context deadline
+var ErrRateLimitWait = errors.New("rate limit wait exceeds context deadline")
+
+// RateLimit configures a token bucket: Rate requests per second on average,
+// with bursts of up to Burst requests. A zero Rate disables the limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiterStats reports how much the rate limiter has throttled requests
+type RateLimiterStats struct {
+	Requests      int64         // requests that passed through the limiter
+	Throttled     int64         // requests that had to wait
+	Rejected      int64         // requests that failed because the wait would exceed their deadline
+	ThrottledTime time.Duration // total time spent waiting
+}
+
+// RateLimiter limits requests with a global token bucket and one bucket per
+// access token. It is safe for concurrent use and meant to be shared by all
+// goroutines using a Client.
+type RateLimiter struct {
+	global   RateLimit
+	perToken RateLimit
+
+	mu      sync.Mutex
+	all     *tokenBucket
+	buckets map[[sha256.Size]byte]*tokenBucket
+
+	requests  atomic.Int64
+	throttled atomic.Int64
+	rejected  atomic.Int64
+	waited    atomic.Int64
+}
+
+// NewRateLimiter creates a rate limiter with a global limit and a per-access-token limit
+func NewRateLimiter(global, perToken RateLimit) *RateLimiter {
+	return &RateLimiter{
+		global:   global,
+		perToken: perToken,
+		all:      newTokenBucket(global),
+		buckets:  map[[sha256.Size]byte]*tokenBucket{},
+	}
+}
+
+// Wait blocks until a request authenticated with token may be sent.
+// It returns ErrRateLimitWait right away if ctx would expire before then,
+// or ctx's error if it ends while waiting. Either way the request's place
+// in the buckets is handed back.
+func (l *RateLimiter) Wait(ctx context.Context, token string) error {
+	l.requests.Add(1)
+
+	now := time.Now()
+
+	l.mu.Lock()
+	buckets := []*tokenBucket{l.all}
+	if token != "" && l.perToken.Rate > 0 {
+		buckets = append(buckets, l.bucket(token, now))
+	}
+
+	var delay time.Duration
+	for _, bucket := range buckets {
+		delay = max(delay, bucket.reserve(now))
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		for _, bucket := range buckets {
+			bucket.cancel(now)
+		}
+		l.mu.Unlock()
+		l.rejected.Add(1)
+		return ErrRateLimitWait
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	l.throttled.Add(1)
+	defer func() { l.waited.Add(int64(time.Since(now))) }()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		// Give the reservation back so that cancelled requests don't delay the others
+		l.mu.Lock()
+		for _, bucket := range buckets {
+			bucket.cancel(time.Now())
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Stats returns the throttling counters accumulated so far
+func (l *RateLimiter) Stats() RateLimiterStats {
+	return RateLimiterStats{
+		Requests:      l.requests.Load(),
+		Throttled:     l.throttled.Load(),
+		Rejected:      l.rejected.Load(),
+		ThrottledTime: time.Duration(l.waited.Load()),
+	}
+}
+
+// bucket returns the bucket of an access token. The caller must hold l.mu.
+func (l *RateLimiter) bucket(token string, now time.Time) *tokenBucket {
+	key := sha256.Sum256([]byte(token))
+	if bucket, ok := l.buckets[key]; ok {
+		return bucket
+	}
+
+	if len(l.buckets) >= maxTokenBuckets {
+		for k, bucket := range l.buckets {
+			if bucket.full(now) {
+				delete(l.buckets, k)
+			}
+		}
+	}
+	if len(l.buckets) >= maxTokenBuckets {
+		// Every bucket is in use: forget the one idle the longest, giving
+		// that token a fresh burst if it comes back
+		var oldest [sha256.Size]byte
+		var oldestLast time.Time
+		for k, bucket := range l.buckets {
+			if oldestLast.IsZero() || bucket.last.Before(oldestLast) {
+				oldest, oldestLast = k, bucket.last
+			}
+		}
+		delete(l.buckets, oldest)
+	}
+
+	bucket := newTokenBucket(l.perToken)
+	l.buckets[key] = bucket
+	return bucket
+}
+
+// tokenBucket is a token bucket that hands out reservations, letting the
+// token count go negative so that waiters are served in order
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	return &tokenBucket{limit: limit, tokens: float64(max(limit.Burst, 1))}
+}
+
+func (b *tokenBucket) advance(now time.Time) {
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = math.Min(float64(max(b.limit.Burst, 1)), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	}
+	b.last = now
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if b.limit.Rate <= 0 {
+		return 0
+	}
+
+	b.advance(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+}
+
+// cancel returns a token taken by reserve, without filling the bucket past its burst
+func (b *tokenBucket) cancel(now time.Time) {
+	if b.limit.Rate > 0 {
+		b.advance(now)
+		b.tokens = math.Min(float64(max(b.limit.Burst, 1)), b.tokens+1)
+	}
+}
+
+func (b *tokenBucket) full(now time.Time) bool {
+	b.advance(now)
+	return b.tokens >= float64(max(b.limit.Burst, 1))
+}
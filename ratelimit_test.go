@@ -0,0 +1,70 @@
+package teller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	tests := []struct {
+		name          string
+		global        RateLimit
+		perToken      RateLimit
+		tokens        []string
+		wantRejected  int64
+		wantThrottled int64
+	}{
+		{"unlimited", RateLimit{}, RateLimit{}, []string{"a", "a", "a"}, 0, 0},
+		{"within burst", RateLimit{Rate: 1, Burst: 3}, RateLimit{}, []string{"a", "b", "c"}, 0, 0},
+		{"global limit", RateLimit{Rate: 1, Burst: 1}, RateLimit{}, []string{"a", "b"}, 1, 0},
+		{"per-token limit", RateLimit{}, RateLimit{Rate: 1, Burst: 1}, []string{"a", "b", "a"}, 1, 0},
+		{"short wait", RateLimit{Rate: 100, Burst: 1}, RateLimit{}, []string{"a", "a"}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.global, tt.perToken)
+			for _, token := range tt.tokens {
+				// Long enough for a 10ms wait, too short for a 1s one
+				ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+				err := limiter.Wait(ctx, token)
+				cancel()
+				if err != nil && !errors.Is(err, ErrRateLimitWait) {
+					t.Fatalf("Wait(%q) error = %v", token, err)
+				}
+			}
+
+			stats := limiter.Stats()
+			if stats.Requests != int64(len(tt.tokens)) || stats.Rejected != tt.wantRejected || stats.Throttled != tt.wantThrottled {
+				t.Errorf("Stats() = %+v, want %d requests, %d rejected, %d throttled",
+					stats, len(tt.tokens), tt.wantRejected, tt.wantThrottled)
+			}
+		})
+	}
+}
+
+func TestRateLimiterBucketCap(t *testing.T) {
+	// A slow refill keeps every bucket in use, so none is dropped for being full
+	limiter := NewRateLimiter(RateLimit{}, RateLimit{Rate: 0.001, Burst: 1})
+
+	for i := range maxTokenBuckets + 100 {
+		if err := limiter.Wait(context.Background(), fmt.Sprintf("token-%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := len(limiter.buckets); n > maxTokenBuckets {
+		t.Errorf("len(buckets) = %d, want at most %d", n, maxTokenBuckets)
+	}
+
+	// The most recent token keeps its bucket and stays limited
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	token := fmt.Sprintf("token-%d", maxTokenBuckets+99)
+	if err := limiter.Wait(ctx, token); !errors.Is(err, ErrRateLimitWait) {
+		t.Errorf("Wait(%q) error = %v, want ErrRateLimitWait", token, err)
+	}
+}
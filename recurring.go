@@ -0,0 +1,298 @@
+package teller
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+type Cadence = string
+
+const (
+	CadenceWeekly   Cadence = "weekly"
+	CadenceBiweekly Cadence = "biweekly"
+	// CadenceSemimonthly is twice a month on fixed days, such as the 1st and
+	// the 15th. It is a pay frequency of AnalyzeIncome, DetectRecurring
+	// reports such series as biweekly.
+	CadenceSemimonthly Cadence = "semimonthly"
+	CadenceMonthly     Cadence = "monthly"
+	CadenceAnnual      Cadence = "annual"
+)
+
+// cadenceSpec describes the intervals, in days, accepted for a cadence and
+// how late a charge may be before it counts as missed. Cadences with dates
+// further test the dates of the charges.
+type cadenceSpec struct {
+	cadence        Cadence
+	min, max       int
+	grace          int
+	minOccurrences int
+	dates          func(charges []recurringCharge) bool
+}
+
+var cadenceSpecs = []cadenceSpec{
+	{cadence: CadenceWeekly, min: 6, max: 8, grace: 3, minOccurrences: 3},
+	{cadence: CadenceBiweekly, min: 12, max: 16, grace: 4, minOccurrences: 3},
+	{cadence: CadenceMonthly, min: 27, max: 34, grace: 7, minOccurrences: 3},
+	{cadence: CadenceAnnual, min: 350, max: 380, grace: 30, minOccurrences: 2},
+}
+
+// RecurringOptions tunes DetectRecurring
+type RecurringOptions struct {
+	// Now is the date missed charges are judged against, defaults to today
+	Now time.Time
+	// AmountTolerance is the relative difference between amounts of the same
+	// series, defaults to 0.2. Larger changes split merchants into several series,
+	// unless the merchant's charges are regular as a whole.
+	AmountTolerance float64
+	// PriceChangeTolerance is the relative difference between the latest two
+	// charges above which PriceChanged is set, defaults to 0.02, so that
+	// currency conversion and tax rounding do not count as price changes
+	PriceChangeTolerance float64
+	// Normalizer groups transactions by merchant, defaults to NormalizeMerchant's
+	Normalizer *MerchantNormalizer
+}
+
+// RecurringSeries is a set of transactions repeating at a regular cadence, such as a subscription
+type RecurringSeries struct {
+	Merchant Merchant
+	Cadence  Cadence
+	// Transactions are the charges of the series, oldest first
+	Transactions []TellerTransaction
+	// AccountID is the account of the latest charge
+	AccountID string
+	// LastDate and LastAmount describe the latest charge
+	LastDate   time.Time
+	LastAmount Amount
+	// NextDate and NextAmount predict the next charge
+	NextDate   time.Time
+	NextAmount Amount
+	// Missed is set when the next charge is overdue by more than the cadence allows
+	Missed bool
+	// PriceChanged is set when the latest charge differs from the one before,
+	// PreviousAmount, by more than RecurringOptions.PriceChangeTolerance
+	PriceChanged   bool
+	PreviousAmount Amount
+}
+
+type recurringCharge struct {
+	transaction TellerTransaction
+	date        time.Time
+	amount      Amount
+}
+
+// DetectRecurring finds recurring charges among transactions, e.g. those of
+// TransactionModule.List. Transactions are grouped by merchant and amount,
+// pending transactions are ignored. Series are returned by next expected date.
+func DetectRecurring(transactions []TellerTransaction, options *RecurringOptions) []RecurringSeries {
+	if options == nil {
+		options = &RecurringOptions{}
+	}
+
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	tolerance := options.AmountTolerance
+	if tolerance <= 0 {
+		tolerance = 0.2
+	}
+
+	priceTolerance := options.PriceChangeTolerance
+	if priceTolerance <= 0 {
+		priceTolerance = 0.02
+	}
+
+	normalizer := options.Normalizer
+	if normalizer == nil {
+		normalizer = defaultMerchantNormalizer
+	}
+
+	type groupKey struct {
+		merchant string
+		credit   bool
+	}
+
+	groups := map[groupKey][]recurringCharge{}
+	merchants := map[string]Merchant{}
+	for _, transaction := range transactions {
+		if transaction.Status == TellerTransactionStatusTypePending {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, transaction.Date)
+		if err != nil {
+			continue
+		}
+		amount, err := ParseAmount(transaction.Amount)
+		if err != nil || amount == 0 {
+			continue
+		}
+
+		merchant := normalizer.Transaction(transaction)
+		if merchant.Key == "" {
+			continue
+		}
+
+		key := groupKey{merchant: merchant.Key, credit: amount > 0}
+		groups[key] = append(groups[key], recurringCharge{transaction: transaction, date: date, amount: amount})
+		merchants[merchant.Key] = merchant
+	}
+
+	var result []RecurringSeries
+	for key, charges := range groups {
+		sort.SliceStable(charges, func(i, j int) bool { return charges[i].date.Before(charges[j].date) })
+
+		if series, ok := recurringSeries(charges, merchants[key.merchant], now, priceTolerance); ok {
+			result = append(result, series)
+			continue
+		}
+
+		for _, cluster := range amountClusters(charges, tolerance) {
+			if series, ok := recurringSeries(cluster, merchants[key.merchant], now, priceTolerance); ok {
+				result = append(result, series)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].NextDate.Equal(result[j].NextDate) {
+			return result[i].NextDate.Before(result[j].NextDate)
+		}
+		return result[i].Merchant.Key < result[j].Merchant.Key
+	})
+
+	return result
+}
+
+// amountClusters splits charges, sorted by date, into groups of similar
+// amounts, each sorted by date
+func amountClusters(charges []recurringCharge, tolerance float64) [][]recurringCharge {
+	byAmount := slices.Clone(charges)
+	sort.SliceStable(byAmount, func(i, j int) bool { return byAmount[i].amount.Abs() < byAmount[j].amount.Abs() })
+
+	var clusters [][]recurringCharge
+	var current []recurringCharge
+	for _, charge := range byAmount {
+		if len(current) > 0 && float64(charge.amount.Abs()-current[0].amount.Abs()) > tolerance*float64(current[0].amount.Abs()) {
+			clusters = append(clusters, current)
+			current = nil
+		}
+		current = append(current, charge)
+	}
+	if len(current) > 0 {
+		clusters = append(clusters, current)
+	}
+
+	for _, cluster := range clusters {
+		sort.SliceStable(cluster, func(i, j int) bool { return cluster[i].date.Before(cluster[j].date) })
+	}
+
+	return clusters
+}
+
+// recurringSeries builds a series from charges sorted by date if their intervals follow a cadence
+func recurringSeries(charges []recurringCharge, merchant Merchant, now time.Time, priceTolerance float64) (RecurringSeries, bool) {
+	if len(charges) < 2 {
+		return RecurringSeries{}, false
+	}
+
+	intervals, ok := chargeIntervals(charges)
+	if !ok {
+		return RecurringSeries{}, false
+	}
+
+	spec, ok := matchCadence(charges, intervals, cadenceSpecs)
+	if !ok || len(charges) < spec.minOccurrences {
+		return RecurringSeries{}, false
+	}
+
+	last := charges[len(charges)-1]
+	previous := charges[len(charges)-2]
+
+	series := RecurringSeries{
+		Merchant:       merchant,
+		Cadence:        spec.cadence,
+		AccountID:      last.transaction.AccountID,
+		LastDate:       last.date,
+		LastAmount:     last.amount,
+		NextDate:       nextCharge(last.date, spec.cadence),
+		NextAmount:     last.amount,
+		PriceChanged:   float64((last.amount - previous.amount).Abs()) > priceTolerance*float64(previous.amount.Abs()),
+		PreviousAmount: previous.amount,
+	}
+	for _, charge := range charges {
+		series.Transactions = append(series.Transactions, charge.transaction)
+	}
+
+	series.Missed = now.After(series.NextDate.AddDate(0, 0, spec.grace))
+
+	return series, true
+}
+
+// chargeIntervals returns the days between consecutive charges sorted by
+// date. Several charges on one day are not a cadence and return false.
+func chargeIntervals(charges []recurringCharge) ([]int, bool) {
+	intervals := make([]int, 0, len(charges)-1)
+	for i := 1; i < len(charges); i++ {
+		days := int(charges[i].date.Sub(charges[i-1].date).Hours() / 24)
+		if days == 0 {
+			return nil, false
+		}
+		intervals = append(intervals, days)
+	}
+	return intervals, true
+}
+
+// matchCadence returns the first cadence that the median interval of
+// charges falls in and whose date test they pass, if at least three
+// quarters of the intervals are regular. An interval covering two periods,
+// a single skipped charge, still counts as regular.
+func matchCadence(charges []recurringCharge, intervals []int, specs []cadenceSpec) (cadenceSpec, bool) {
+	sorted := slices.Clone(intervals)
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+
+	for _, spec := range specs {
+		if median < spec.min || median > spec.max || spec.dates != nil && !spec.dates(charges) {
+			continue
+		}
+
+		return spec, regularity(intervals, spec) >= 0.75
+	}
+
+	return cadenceSpec{}, false
+}
+
+// regularity is the share of intervals matching the cadence, counting skipped periods as regular
+func regularity(intervals []int, spec cadenceSpec) float64 {
+	regular := 0
+	for _, days := range intervals {
+		if days >= spec.min && days <= spec.max || days >= 2*spec.min && days <= 2*spec.max {
+			regular++
+		}
+	}
+	return float64(regular) / float64(len(intervals))
+}
+
+func nextCharge(last time.Time, cadence Cadence) time.Time {
+	switch cadence {
+	case CadenceWeekly:
+		return last.AddDate(0, 0, 7)
+	case CadenceBiweekly:
+		return last.AddDate(0, 0, 14)
+	case CadenceMonthly:
+		return addMonthsClamped(last, 1)
+	default:
+		return addMonthsClamped(last, 12)
+	}
+}
+
+// addMonthsClamped adds months to t, keeping the day of month but clamping
+// it to the end of shorter months, e.g. Jan 31 plus a month is Feb 28
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).AddDate(0, months, 0)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	return firstOfMonth.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
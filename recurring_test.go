@@ -0,0 +1,166 @@
+package teller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDetectRecurringCadence(t *testing.T) {
+	type cadenceCase struct {
+		name  string
+		dates []time.Time
+		want  Cadence
+		next  time.Time
+	}
+	var tests []cadenceCase
+
+	for start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); start.Year() == 2025; start = start.AddDate(0, 0, 3) {
+		for n := 4; n <= 8; n++ {
+			dates := everyDays(start, 14, n)
+			tests = append(tests, cadenceCase{
+				fmt.Sprintf("biweekly %d from %s", n, start.Format(dateLayout)),
+				dates, CadenceBiweekly, dates[n-1].AddDate(0, 0, 14),
+			})
+		}
+	}
+
+	// Charges twice a month are reported as biweekly, the next one fourteen days on
+	for month := 1; month <= 12; month += 3 {
+		start := time.Date(2025, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		dates := semimonthlyDates(start, 15, 6)
+		tests = append(tests, cadenceCase{
+			"twice a month from " + start.Format("2006-01"),
+			dates, CadenceBiweekly, dates[5].AddDate(0, 0, 14),
+		})
+	}
+
+	tests = append(tests,
+		cadenceCase{"weekly", everyDays(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), 7, 5), CadenceWeekly, time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC)},
+		cadenceCase{"monthly at the end of the month", []time.Time{
+			time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		}, CadenceMonthly, time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)},
+		cadenceCase{"monthly with a skipped month", []time.Time{
+			time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+		}, CadenceMonthly, time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)},
+		cadenceCase{"annual", []time.Time{
+			time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+		}, CadenceAnnual, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)},
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := testTransactions("acc_1", "NETFLIX.COM", "-15.49", tt.dates)
+			now := tt.dates[len(tt.dates)-1].AddDate(0, 0, 1)
+
+			series := DetectRecurring(transactions, &RecurringOptions{Now: now})
+			if len(series) != 1 {
+				t.Fatalf("DetectRecurring found %d series, want 1", len(series))
+			}
+			if got := series[0]; got.Cadence != tt.want || !got.NextDate.Equal(tt.next) || got.Missed {
+				t.Errorf("Cadence, NextDate, Missed = %s, %s, %v, want %s, %s, false",
+					got.Cadence, got.NextDate.Format(dateLayout), got.Missed, tt.want, tt.next.Format(dateLayout))
+			}
+		})
+	}
+}
+
+func TestDetectRecurringIrregular(t *testing.T) {
+	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		dates []time.Time
+	}{
+		{"too few", everyDays(start, 30, 2)},
+		{"random", []time.Time{start, start.AddDate(0, 0, 3), start.AddDate(0, 0, 19), start.AddDate(0, 0, 60), start.AddDate(0, 0, 64)}},
+		{"same day", []time.Time{start, start, start.AddDate(0, 1, 0), start.AddDate(0, 2, 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := testTransactions("acc_1", "CORNER DELI", "-9.50", tt.dates)
+			if series := DetectRecurring(transactions, &RecurringOptions{Now: start.AddDate(0, 3, 0)}); len(series) != 0 {
+				t.Errorf("DetectRecurring found %d series, want none", len(series))
+			}
+		})
+	}
+}
+
+func TestDetectRecurringChanges(t *testing.T) {
+	start := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	dates := everyDays(start, 28, 4)
+	last := dates[3]
+
+	tests := []struct {
+		name             string
+		amounts          []string
+		now              time.Time
+		wantMissed       bool
+		wantPriceChanged bool
+	}{
+		{"steady", []string{"-15.49", "-15.49", "-15.49", "-15.49"}, last.AddDate(0, 0, 1), false, false},
+		{"within grace", []string{"-15.49", "-15.49", "-15.49", "-15.49"}, last.AddDate(0, 0, 30), false, false},
+		{"missed", []string{"-15.49", "-15.49", "-15.49", "-15.49"}, last.AddDate(0, 0, 40), true, false},
+		{"price increase", []string{"-15.49", "-15.49", "-15.49", "-17.99"}, last.AddDate(0, 0, 1), false, true},
+		{"conversion rounding", []string{"-15.49", "-15.49", "-15.52", "-15.47"}, last.AddDate(0, 0, 1), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transactions []TellerTransaction
+			for i, amount := range tt.amounts {
+				transactions = append(transactions, testTransactions("acc_1", "SPOTIFY USA", amount, dates[i:i+1])...)
+			}
+
+			series := DetectRecurring(transactions, &RecurringOptions{Now: tt.now})
+			if len(series) != 1 {
+				t.Fatalf("DetectRecurring found %d series, want 1", len(series))
+			}
+			got := series[0]
+			if got.Missed != tt.wantMissed || got.PriceChanged != tt.wantPriceChanged {
+				t.Errorf("Missed, PriceChanged = %v, %v, want %v, %v", got.Missed, got.PriceChanged, tt.wantMissed, tt.wantPriceChanged)
+			}
+			if got.Merchant.Name != "Spotify" || got.LastAmount.String() != tt.amounts[3] || got.PreviousAmount.String() != tt.amounts[2] {
+				t.Errorf("Merchant, LastAmount, PreviousAmount = %s, %s, %s", got.Merchant.Name, got.LastAmount, got.PreviousAmount)
+			}
+		})
+	}
+}
+
+func TestDetectRecurringSplitsAmounts(t *testing.T) {
+	start := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	// Two plans billed by one merchant on different days
+	transactions := append(
+		testTransactions("acc_1", "APPLE.COM/BILL", "-0.99", everyDays(start, 30, 4)),
+		testTransactions("acc_2", "APPLE.COM/BILL", "-9.99", everyDays(start.AddDate(0, 0, 11), 30, 4))...,
+	)
+	for i := range transactions {
+		transactions[i].ID = fmt.Sprintf("txn_%d", i)
+	}
+	// Pending charges are ignored
+	pending := testTransactions("acc_1", "APPLE.COM/BILL", "-4.99", []time.Time{start.AddDate(0, 0, 5)})
+	pending[0].Status = TellerTransactionStatusTypePending
+	transactions = append(transactions, pending...)
+
+	series := DetectRecurring(transactions, &RecurringOptions{Now: start.AddDate(0, 4, 0)})
+	if len(series) != 2 {
+		t.Fatalf("DetectRecurring found %d series, want 2", len(series))
+	}
+	for i, want := range []struct {
+		amount    string
+		accountID string
+	}{{"-0.99", "acc_1"}, {"-9.99", "acc_2"}} {
+		if got := series[i]; got.Cadence != CadenceMonthly || got.LastAmount.String() != want.amount || got.AccountID != want.accountID || len(got.Transactions) != 4 {
+			t.Errorf("series[%d] = %s %s on %s with %d charges, want monthly %s on %s with 4",
+				i, got.Cadence, got.LastAmount, got.AccountID, len(got.Transactions), want.amount, want.accountID)
+		}
+	}
+}
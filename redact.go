@@ -0,0 +1,284 @@
+package teller
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Values that identify accounts or people are masked when the types holding
+// them are printed with fmt or logged with slog. JSON encoding is unaffected.
+// Use the Reveal methods when the raw values must be printed.
+
+// MaskLast4 masks all but the last four characters of s
+func MaskLast4(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 4 {
+		return strings.Repeat("*", len(s))
+	}
+	return "****" + s[len(s)-4:]
+}
+
+// MaskToken masks an access token, keeping its "token_" prefix and last four characters
+func MaskToken(token string) string {
+	if rest, ok := strings.CutPrefix(token, "token_"); ok {
+		return "token_" + MaskLast4(rest)
+	}
+	return MaskLast4(token)
+}
+
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return MaskLast4(email)
+	}
+	return firstRune(local) + "****@" + domain
+}
+
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		words[i] = firstRune(word) + "***"
+	}
+	return strings.Join(words, " ")
+}
+
+func firstRune(s string) string {
+	for _, r := range s {
+		return string(r)
+	}
+	return ""
+}
+
+func maskOptional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	masked := MaskLast4(*s)
+	return &masked
+}
+
+func optionalValue(s *string) slog.Value {
+	if s == nil {
+		return slog.AnyValue(nil)
+	}
+	return slog.StringValue(*s)
+}
+
+// formatMasked prints v, a masked copy, with the verb and flags of the original directive
+func formatMasked(f fmt.State, verb rune, v any) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), v)
+}
+
+// RevealedAccountDetails prints account details without masking
+type RevealedAccountDetails TellerAccountDetails
+
+// Reveal returns the details in a form that prints the raw account and routing numbers
+func (d TellerAccountDetails) Reveal() RevealedAccountDetails {
+	return RevealedAccountDetails(d)
+}
+
+func (d TellerAccountDetails) masked() RevealedAccountDetails {
+	masked := RevealedAccountDetails(d)
+	masked.AccountNumber = MaskLast4(d.AccountNumber)
+	masked.RoutingNumbers.ACH = maskOptional(d.RoutingNumbers.ACH)
+	masked.RoutingNumbers.Wire = maskOptional(d.RoutingNumbers.Wire)
+	masked.RoutingNumbers.BACS = maskOptional(d.RoutingNumbers.BACS)
+	return masked
+}
+
+// Format implements fmt.Formatter, masking account and routing numbers
+func (d TellerAccountDetails) Format(f fmt.State, verb rune) {
+	formatMasked(f, verb, d.masked())
+}
+
+// String returns the details with account and routing numbers masked
+func (d TellerAccountDetails) String() string {
+	return fmt.Sprintf("%+v", d.masked())
+}
+
+// LogValue implements slog.LogValuer, masking account and routing numbers
+func (d TellerAccountDetails) LogValue() slog.Value {
+	masked := d.masked()
+	return slog.GroupValue(
+		slog.String("account_id", masked.AccountID),
+		slog.String("account_number", masked.AccountNumber),
+		slog.Group("routing_numbers",
+			slog.Attr{Key: "ach", Value: optionalValue(masked.RoutingNumbers.ACH)},
+			slog.Attr{Key: "wire", Value: optionalValue(masked.RoutingNumbers.Wire)},
+			slog.Attr{Key: "bacs", Value: optionalValue(masked.RoutingNumbers.BACS)},
+		),
+	)
+}
+
+// RevealedOwner prints an owner without masking
+type RevealedOwner TellerOwner
+
+// Reveal returns the owner in a form that prints raw names and contact details
+func (o TellerOwner) Reveal() RevealedOwner {
+	return RevealedOwner(o)
+}
+
+func (o TellerOwner) masked() RevealedOwner {
+	masked := RevealedOwner(o)
+
+	masked.Names = append(masked.Names[:0:0], o.Names...)
+	for i := range masked.Names {
+		masked.Names[i].Data = maskName(masked.Names[i].Data)
+	}
+
+	masked.Addresses = append(masked.Addresses[:0:0], o.Addresses...)
+	for i := range masked.Addresses {
+		masked.Addresses[i].Street = "****"
+		masked.Addresses[i].PostalCode = "****"
+	}
+
+	masked.PhoneNumbers = append(masked.PhoneNumbers[:0:0], o.PhoneNumbers...)
+	for i := range masked.PhoneNumbers {
+		masked.PhoneNumbers[i].Data = MaskLast4(masked.PhoneNumbers[i].Data)
+	}
+
+	masked.Emails = append(masked.Emails[:0:0], o.Emails...)
+	for i := range masked.Emails {
+		masked.Emails[i].Data = maskEmail(masked.Emails[i].Data)
+	}
+
+	return masked
+}
+
+// Format implements fmt.Formatter, masking names, addresses, phone numbers and emails
+func (o TellerOwner) Format(f fmt.State, verb rune) {
+	formatMasked(f, verb, o.masked())
+}
+
+// String returns the owner with names, addresses, phone numbers and emails masked
+func (o TellerOwner) String() string {
+	return fmt.Sprintf("%+v", o.masked())
+}
+
+// LogValue implements slog.LogValuer, masking names, addresses, phone numbers and emails
+func (o TellerOwner) LogValue() slog.Value {
+	masked := o.masked()
+
+	var names, phones, emails []string
+	for _, name := range masked.Names {
+		names = append(names, name.Data)
+	}
+	for _, phone := range masked.PhoneNumbers {
+		phones = append(phones, phone.Data)
+	}
+	for _, email := range masked.Emails {
+		emails = append(emails, email.Data)
+	}
+
+	var addresses []string
+	for _, address := range masked.Addresses {
+		addresses = append(addresses, strings.Join([]string{address.City, address.Region, address.CountryCode}, ", "))
+	}
+
+	return slog.GroupValue(
+		slog.String("type", masked.Type),
+		slog.Any("names", names),
+		slog.Any("addresses", addresses),
+		slog.Any("phone_numbers", phones),
+		slog.Any("emails", emails),
+	)
+}
+
+// RevealedOptionsBase prints options with the raw access token
+type RevealedOptionsBase TellerOptionsBase
+
+// Reveal returns the options in a form that prints the raw access token
+func (o TellerOptionsBase) Reveal() RevealedOptionsBase {
+	return RevealedOptionsBase(o)
+}
+
+// Format implements fmt.Formatter, masking the access token
+func (o TellerOptionsBase) Format(f fmt.State, verb rune) {
+	formatMasked(f, verb, RevealedOptionsBase{AccessToken: MaskToken(o.AccessToken)})
+}
+
+// String returns the options with the access token masked
+func (o TellerOptionsBase) String() string {
+	return fmt.Sprintf("%+v", RevealedOptionsBase{AccessToken: MaskToken(o.AccessToken)})
+}
+
+// LogValue implements slog.LogValuer, masking the access token
+func (o TellerOptionsBase) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("access_token", MaskToken(o.AccessToken)))
+}
+
+// RevealedOptionsPagination prints pagination options with the raw access token
+type RevealedOptionsPagination struct {
+	RevealedOptionsBase
+	Cursor    *string
+	Limit     *int
+	StartDate *string
+	EndDate   *string
+}
+
+// Reveal returns the options in a form that prints the raw access token
+func (o TellerOptionsPagination) Reveal() RevealedOptionsPagination {
+	return RevealedOptionsPagination{
+		RevealedOptionsBase: o.TellerOptionsBase.Reveal(),
+		Cursor:              o.Cursor,
+		Limit:               o.Limit,
+		StartDate:           o.StartDate,
+		EndDate:             o.EndDate,
+	}
+}
+
+func (o TellerOptionsPagination) masked() RevealedOptionsPagination {
+	masked := o.Reveal()
+	masked.AccessToken = MaskToken(o.AccessToken)
+	return masked
+}
+
+// Format implements fmt.Formatter, masking the access token
+func (o TellerOptionsPagination) Format(f fmt.State, verb rune) {
+	formatMasked(f, verb, o.masked())
+}
+
+// String returns the options with the access token masked
+func (o TellerOptionsPagination) String() string {
+	return fmt.Sprintf("%+v", o.masked())
+}
+
+// LogValue implements slog.LogValuer, masking the access token
+func (o TellerOptionsPagination) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("access_token", MaskToken(o.AccessToken))}
+	if o.Cursor != nil {
+		attrs = append(attrs, slog.String("cursor", *o.Cursor))
+	}
+	if o.Limit != nil {
+		attrs = append(attrs, slog.Int("limit", *o.Limit))
+	}
+	if o.StartDate != nil {
+		attrs = append(attrs, slog.String("start_date", *o.StartDate))
+	}
+	if o.EndDate != nil {
+		attrs = append(attrs, slog.String("end_date", *o.EndDate))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// String describes the client without revealing its access token. Like
+// Format it has a value receiver, so that printing a Client value, or a
+// struct embedding one, masks the token too.
+func (c Client) String() string {
+	s := fmt.Sprintf("teller.Client{baseURL: %s", c.baseURL)
+	if c.accessToken != "" {
+		s += ", accessToken: " + MaskToken(c.accessToken)
+	}
+	if c.userID != "" {
+		s += ", userID: " + c.userID
+	}
+	return s + "}"
+}
+
+// Format implements fmt.Formatter, printing String for every verb
+func (c Client) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, c.String())
+}
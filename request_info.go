@@ -0,0 +1,92 @@
+package teller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+type requestInfoKey struct{}
+
+// RequestInfo describes an API request. It is attached to the context of
+// every outgoing *http.Request, so that transports wrapping the client's
+// can label requests without parsing URLs.
+type RequestInfo struct {
+	Method   string
+	Endpoint Endpoint
+	// AccountID is set for account-scoped endpoints
+	AccountID string
+	// InstitutionID is set for account-scoped endpoints once the client has seen the account
+	InstitutionID string
+	// UserID is set when the client was scoped with ForUser
+	UserID string
+	// TokenFingerprint identifies the access token without revealing it, see TokenFingerprint
+	TokenFingerprint string
+	// Attempt counts from 1 and increases when a rate limited request is retried
+	Attempt int
+}
+
+// TokenFingerprint returns a short, stable identifier of an access token
+// that is safe to log and use as a metrics or cache key, or "" for no token
+func TokenFingerprint(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// RequestInfoFromContext returns the RequestInfo attached to a request's context
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
+
+func contextWithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// accountIDFromPath extracts the account ID of paths like /accounts/{id}/...
+func accountIDFromPath(path string) string {
+	rest, ok := strings.CutPrefix(path, "/accounts/")
+	if !ok {
+		return ""
+	}
+
+	id, _, _ := strings.Cut(rest, "/")
+	id, _, _ = strings.Cut(id, "?")
+	return id
+}
+
+// institutionIndex remembers the institution of every account the client has decoded
+type institutionIndex struct {
+	accounts sync.Map // account ID -> institution ID
+}
+
+func (i *institutionIndex) lookup(accountID string) string {
+	if i == nil || accountID == "" {
+		return ""
+	}
+
+	institutionID, _ := i.accounts.Load(accountID)
+	s, _ := institutionID.(string)
+	return s
+}
+
+// record stores the institutions of accounts contained in a decoded response
+func (i *institutionIndex) record(result any) {
+	if i == nil {
+		return
+	}
+
+	switch v := result.(type) {
+	case *[]TellerAccount:
+		for _, account := range *v {
+			i.accounts.Store(account.ID, account.Institution.ID)
+		}
+	case *TellerAccount:
+		i.accounts.Store(v.ID, v.Institution.ID)
+	}
+}
@@ -0,0 +1,260 @@
+package teller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule enriches the transactions matching all of its conditions. Empty
+// conditions match every transaction. Rules with a higher Priority are
+// evaluated first, rules of equal priority in the order they are listed.
+type Rule struct {
+	// ID identifies the rule in explanations
+	ID       string `json:"id" yaml:"id"`
+	Priority int    `json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	// Description is a regular expression matched against the description, ignoring case
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Counterparty is a regular expression matched against the counterparty name, ignoring case
+	Counterparty     string                            `json:"counterparty,omitempty" yaml:"counterparty,omitempty"`
+	CounterpartyType TellerTransactionCounterPartyType `json:"counterparty_type,omitempty" yaml:"counterparty_type,omitempty"`
+	// MinAmount and MaxAmount bound the signed amount, inclusive
+	MinAmount *Amount `json:"min_amount,omitempty" yaml:"min_amount,omitempty"`
+	MaxAmount *Amount `json:"max_amount,omitempty" yaml:"max_amount,omitempty"`
+	// AccountIDs matches transactions of any of the accounts
+	AccountIDs []string `json:"account_ids,omitempty" yaml:"account_ids,omitempty"`
+	// Types matches transactions of any of the types, e.g. "card_payment"
+	Types []string `json:"types,omitempty" yaml:"types,omitempty"`
+	// Categories matches transactions Teller put in any of the categories
+	Categories []TransactionCategory `json:"categories,omitempty" yaml:"categories,omitempty"`
+
+	// Category is assigned by the first matching rule that sets one
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+	// Merchant is the cleaned merchant name, assigned by the first matching rule that sets one
+	Merchant string `json:"merchant,omitempty" yaml:"merchant,omitempty"`
+	// Tags are added by every matching rule
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Stop ends evaluation after this rule matches
+	Stop bool `json:"stop,omitempty" yaml:"stop,omitempty"`
+}
+
+// RuleFile is the format of rule files, in JSON or YAML
+type RuleFile struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// RuleFormat selects how LoadRules decodes rules
+type RuleFormat = string
+
+const (
+	RuleFormatJSON RuleFormat = "json"
+	RuleFormatYAML RuleFormat = "yaml"
+)
+
+// Enrichment is the result of applying a RuleSet to a transaction
+type Enrichment struct {
+	TransactionID string
+	// Category and Merchant are empty when no matching rule sets them
+	Category string
+	Merchant string
+	Tags     []string
+	// CategoryRule and MerchantRule are the IDs of the rules that set Category and Merchant
+	CategoryRule string
+	MerchantRule string
+	// Matched lists the IDs of every matching rule, in evaluation order
+	Matched []string
+}
+
+// Explain describes which rules produced the enrichment
+func (e Enrichment) Explain() string {
+	if len(e.Matched) == 0 {
+		return "no rule matched"
+	}
+
+	parts := []string{"matched " + strings.Join(e.Matched, ", ")}
+	if e.Category != "" {
+		parts = append(parts, fmt.Sprintf("category %q from %s", e.Category, e.CategoryRule))
+	}
+	if e.Merchant != "" {
+		parts = append(parts, fmt.Sprintf("merchant %q from %s", e.Merchant, e.MerchantRule))
+	}
+	if len(e.Tags) > 0 {
+		parts = append(parts, "tags "+strings.Join(e.Tags, ", "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+type compiledRule struct {
+	Rule
+	description  *regexp.Regexp
+	counterparty *regexp.Regexp
+}
+
+// RuleSet applies rules to transactions. It is safe for concurrent use.
+type RuleSet struct {
+	rules []compiledRule
+}
+
+// NewRuleSet compiles rules, ordering them by priority
+func NewRuleSet(rules []Rule) (*RuleSet, error) {
+	compiled := make([]compiledRule, len(rules))
+	for i, rule := range rules {
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("rule %d", i+1)
+		}
+
+		compiled[i] = compiledRule{Rule: rule}
+
+		var err error
+		if compiled[i].description, err = compileRulePattern(rule.Description); err != nil {
+			return nil, fmt.Errorf("%s: description: %w", rule.ID, err)
+		}
+		if compiled[i].counterparty, err = compileRulePattern(rule.Counterparty); err != nil {
+			return nil, fmt.Errorf("%s: counterparty: %w", rule.ID, err)
+		}
+	}
+
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].Priority > compiled[j].Priority
+	})
+
+	return &RuleSet{rules: compiled}, nil
+}
+
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// LoadRules reads a rule file in the given format
+func LoadRules(r io.Reader, format RuleFormat) (*RuleSet, error) {
+	var file RuleFile
+
+	switch format {
+	case RuleFormatJSON:
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&file); err != nil {
+			return nil, err
+		}
+	case RuleFormatYAML:
+		decoder := yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&file); err != nil && err != io.EOF {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown rule format %q", format)
+	}
+
+	return NewRuleSet(file.Rules)
+}
+
+// LoadRuleFile reads a rule file, in YAML if its extension is .yaml or .yml and JSON otherwise
+func LoadRuleFile(path string) (*RuleSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	format := RuleFormatJSON
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		format = RuleFormatYAML
+	}
+
+	rules, err := LoadRules(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Apply evaluates the rules against a transaction
+func (s *RuleSet) Apply(transaction TellerTransaction) Enrichment {
+	enrichment := Enrichment{TransactionID: transaction.ID}
+
+	amount, amountErr := ParseAmount(transaction.Amount)
+
+	for _, rule := range s.rules {
+		if !rule.matches(transaction, amount, amountErr == nil) {
+			continue
+		}
+
+		enrichment.Matched = append(enrichment.Matched, rule.ID)
+
+		if enrichment.Category == "" && rule.Category != "" {
+			enrichment.Category = rule.Category
+			enrichment.CategoryRule = rule.ID
+		}
+		if enrichment.Merchant == "" && rule.Merchant != "" {
+			enrichment.Merchant = rule.Merchant
+			enrichment.MerchantRule = rule.ID
+		}
+		for _, tag := range rule.Tags {
+			if !slices.Contains(enrichment.Tags, tag) {
+				enrichment.Tags = append(enrichment.Tags, tag)
+			}
+		}
+
+		if rule.Stop {
+			break
+		}
+	}
+
+	return enrichment
+}
+
+// Category returns the category the rules assign to a transaction, so that
+// a RuleSet can be used as a CategoryMapper
+func (s *RuleSet) Category(transaction TellerTransaction) string {
+	return s.Apply(transaction).Category
+}
+
+func (r compiledRule) matches(transaction TellerTransaction, amount Amount, hasAmount bool) bool {
+	if r.description != nil && !r.description.MatchString(transaction.Description) {
+		return false
+	}
+
+	counterparty := ""
+	if transaction.Details.Counterparty.Name != nil {
+		counterparty = *transaction.Details.Counterparty.Name
+	}
+	if r.counterparty != nil && !r.counterparty.MatchString(counterparty) {
+		return false
+	}
+	if r.CounterpartyType != "" && r.CounterpartyType != transaction.Details.Counterparty.Type {
+		return false
+	}
+
+	if r.MinAmount != nil && (!hasAmount || amount < *r.MinAmount) {
+		return false
+	}
+	if r.MaxAmount != nil && (!hasAmount || amount > *r.MaxAmount) {
+		return false
+	}
+
+	if len(r.AccountIDs) > 0 && !slices.Contains(r.AccountIDs, transaction.AccountID) {
+		return false
+	}
+	if len(r.Types) > 0 && !slices.Contains(r.Types, transaction.Type) {
+		return false
+	}
+	if len(r.Categories) > 0 && !slices.Contains(r.Categories, transaction.Details.Category) {
+		return false
+	}
+
+	return true
+}
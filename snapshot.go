@@ -0,0 +1,181 @@
+package teller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultSnapshotConcurrency = 4
+
+type SnapshotResource = string
+
+const (
+	SnapshotResourceDetails      SnapshotResource = "details"
+	SnapshotResourceBalances     SnapshotResource = "balances"
+	SnapshotResourceTransactions SnapshotResource = "transactions"
+	SnapshotResourceIdentity     SnapshotResource = "identity"
+)
+
+// SnapshotOptions configures Client.Snapshot
+type SnapshotOptions struct {
+	// Concurrency bounds the number of requests in flight, defaults to 4
+	Concurrency int
+	// Transactions is passed to every transactions request, e.g. to limit the date range.
+	// Its access token is ignored.
+	Transactions *TellerOptionsPagination
+
+	SkipDetails      bool
+	SkipBalances     bool
+	SkipTransactions bool
+	IncludeIdentity  bool
+}
+
+// SnapshotError records a request that failed while taking a snapshot
+type SnapshotError struct {
+	AccountID string // empty for enrollment-wide resources such as identity
+	Resource  SnapshotResource
+	Err       error
+}
+
+func (e *SnapshotError) Error() string {
+	if e.AccountID == "" {
+		return fmt.Sprintf("%s: %v", e.Resource, e.Err)
+	}
+	return fmt.Sprintf("%s of account %s: %v", e.Resource, e.AccountID, e.Err)
+}
+
+func (e *SnapshotError) Unwrap() error {
+	return e.Err
+}
+
+// AccountSnapshot holds everything fetched for one account.
+// Resources that were skipped or failed to load are nil.
+type AccountSnapshot struct {
+	Account      TellerAccount          `json:"account"`
+	Details      *TellerAccountDetails  `json:"details,omitempty"`
+	Balances     *TellerAccountBalances `json:"balances,omitempty"`
+	Transactions []TellerTransaction    `json:"transactions,omitempty"`
+}
+
+// Snapshot is the data available to an access token at a point in time
+type Snapshot struct {
+	Accounts   []AccountSnapshot `json:"accounts"`
+	Identities []TellerIdentity  `json:"identities,omitempty"`
+	Errors     []*SnapshotError  `json:"-"`
+	TakenAt    time.Time         `json:"taken_at"`
+}
+
+// Snapshot fetches the accounts of an access token and then their details,
+// balances and transactions concurrently.
+//
+// Only a failure to list accounts, or ctx ending, aborts the snapshot.
+// Other failed requests are collected in Snapshot.Errors and the
+// corresponding resources are left empty.
+func (c *Client) Snapshot(ctx context.Context, token string, options *SnapshotOptions) (*Snapshot, error) {
+	if options == nil {
+		options = &SnapshotOptions{}
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultSnapshotConcurrency
+	}
+
+	result := &Snapshot{TakenAt: time.Now().UTC()}
+
+	var accounts []TellerAccount
+	if err := c.do(ctx, "GET", EndpointAccounts, "/accounts", token, &accounts); err != nil {
+		return nil, err
+	}
+
+	result.Accounts = make([]AccountSnapshot, len(accounts))
+	for i, account := range accounts {
+		result.Accounts[i].Account = account
+	}
+
+	var mu sync.Mutex
+	record := func(accountID string, resource SnapshotResource, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Errors = append(result.Errors, &SnapshotError{AccountID: accountID, Resource: resource, Err: err})
+	}
+
+	var jobs []func()
+	for i := range result.Accounts {
+		snapshot := &result.Accounts[i]
+		id := snapshot.Account.ID
+
+		if !options.SkipDetails {
+			jobs = append(jobs, func() {
+				var details TellerAccountDetails
+				if err := c.do(ctx, "GET", EndpointAccountDetails, fmt.Sprintf("/accounts/%s/details", id), token, &details); err != nil {
+					record(id, SnapshotResourceDetails, err)
+					return
+				}
+				snapshot.Details = &details
+			})
+		}
+
+		if !options.SkipBalances {
+			jobs = append(jobs, func() {
+				var balances TellerAccountBalances
+				if err := c.do(ctx, "GET", EndpointAccountBalances, fmt.Sprintf("/accounts/%s/balances", id), token, &balances); err != nil {
+					record(id, SnapshotResourceBalances, err)
+					return
+				}
+				snapshot.Balances = &balances
+			})
+		}
+
+		if !options.SkipTransactions {
+			jobs = append(jobs, func() {
+				var transactions []TellerTransaction
+				if err := c.do(ctx, "GET", EndpointTransactions, transactionsPath(id, options.Transactions), token, &transactions); err != nil {
+					record(id, SnapshotResourceTransactions, err)
+					return
+				}
+				snapshot.Transactions = transactions
+			})
+		}
+	}
+
+	if options.IncludeIdentity {
+		jobs = append(jobs, func() {
+			var identities []TellerIdentity
+			if err := c.do(ctx, "GET", EndpointIdentity, "/identity", token, &identities); err != nil {
+				record("", SnapshotResourceIdentity, err)
+				return
+			}
+			result.Identities = identities
+		})
+	}
+
+	queue := make(chan func())
+	var wg sync.WaitGroup
+	for range min(concurrency, len(jobs)) {
+		wg.Go(func() {
+			for job := range queue {
+				job()
+			}
+		})
+	}
+
+dispatch:
+	for _, job := range jobs {
+		select {
+		case queue <- job:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package teller
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by a Store when the requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// BalanceSnapshot represents the balances of an account at a point in time
+type BalanceSnapshot struct {
+	AccountID string    `json:"account_id"`
+	Ledger    string    `json:"ledger"`
+	Available string    `json:"available"`
+	TakenAt   time.Time `json:"taken_at"`
+}
+
+// NewBalanceSnapshot creates a snapshot from balances returned by the API
+func NewBalanceSnapshot(balances TellerAccountBalances, takenAt time.Time) BalanceSnapshot {
+	return BalanceSnapshot{
+		AccountID: balances.AccountID,
+		Ledger:    balances.Ledger,
+		Available: balances.Available,
+		TakenAt:   takenAt,
+	}
+}
+
+// StoreTransactionQuery narrows the transactions returned by a Store.
+// StartDate and EndDate are inclusive and use the API's YYYY-MM-DD format.
+type StoreTransactionQuery struct {
+	AccountID string
+	StartDate *string
+	EndDate   *string
+}
+
+// Store persists data synced from the Teller API.
+//
+// Put methods insert or replace records by ID. Removing an account also
+// removes its balance snapshots, transactions and identity.
+type Store interface {
+	PutAccounts(ctx context.Context, accounts []TellerAccount) error
+	GetAccount(ctx context.Context, id string) (*TellerAccount, error)
+	// ListAccounts returns the accounts of an enrollment, or all accounts if enrollmentID is empty
+	ListAccounts(ctx context.Context, enrollmentID string) ([]TellerAccount, error)
+	RemoveAccount(ctx context.Context, id string) error
+
+	PutBalanceSnapshot(ctx context.Context, snapshot BalanceSnapshot) error
+	// ListBalanceSnapshots returns the snapshots of an account taken in [from, to], oldest first.
+	// A zero from or to leaves that side of the range open.
+	ListBalanceSnapshots(ctx context.Context, accountID string, from, to time.Time) ([]BalanceSnapshot, error)
+
+	PutTransactions(ctx context.Context, transactions []TellerTransaction) error
+	GetTransaction(ctx context.Context, id string) (*TellerTransaction, error)
+	// ListTransactions returns matching transactions, newest first
+	ListTransactions(ctx context.Context, query StoreTransactionQuery) ([]TellerTransaction, error)
+	RemoveTransactions(ctx context.Context, ids []string) error
+
+	PutIdentities(ctx context.Context, identities []TellerIdentity) error
+	GetIdentity(ctx context.Context, accountID string) (*TellerIdentity, error)
+}
@@ -0,0 +1,217 @@
+package teller
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps everything in memory.
+// It is safe for concurrent use.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	accounts     map[string]TellerAccount
+	snapshots    map[string][]BalanceSnapshot
+	transactions map[string]TellerTransaction
+	identities   map[string]TellerIdentity
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		accounts:     map[string]TellerAccount{},
+		snapshots:    map[string][]BalanceSnapshot{},
+		transactions: map[string]TellerTransaction{},
+		identities:   map[string]TellerIdentity{},
+	}
+}
+
+// PutAccounts inserts or replaces accounts
+func (s *MemoryStore) PutAccounts(ctx context.Context, accounts []TellerAccount) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, account := range accounts {
+		s.accounts[account.ID] = account
+	}
+
+	return nil
+}
+
+// GetAccount retrieves a single account by ID
+func (s *MemoryStore) GetAccount(ctx context.Context, id string) (*TellerAccount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	account, ok := s.accounts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &account, nil
+}
+
+// ListAccounts retrieves the accounts of an enrollment, or all accounts if enrollmentID is empty
+func (s *MemoryStore) ListAccounts(ctx context.Context, enrollmentID string) ([]TellerAccount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []TellerAccount{}
+	for _, account := range s.accounts {
+		if enrollmentID == "" || account.EnrollmentID == enrollmentID {
+			result = append(result, account)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+// RemoveAccount deletes an account and everything stored for it
+func (s *MemoryStore) RemoveAccount(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.accounts, id)
+	delete(s.snapshots, id)
+	delete(s.identities, id)
+	for txnID, transaction := range s.transactions {
+		if transaction.AccountID == id {
+			delete(s.transactions, txnID)
+		}
+	}
+
+	return nil
+}
+
+// PutBalanceSnapshot records a balance snapshot
+func (s *MemoryStore) PutBalanceSnapshot(ctx context.Context, snapshot BalanceSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots := s.snapshots[snapshot.AccountID]
+	i := sort.Search(len(snapshots), func(i int) bool { return !snapshots[i].TakenAt.Before(snapshot.TakenAt) })
+	if i < len(snapshots) && snapshots[i].TakenAt.Equal(snapshot.TakenAt) {
+		snapshots[i] = snapshot
+		return nil
+	}
+
+	snapshots = append(snapshots, BalanceSnapshot{})
+	copy(snapshots[i+1:], snapshots[i:])
+	snapshots[i] = snapshot
+	s.snapshots[snapshot.AccountID] = snapshots
+
+	return nil
+}
+
+// ListBalanceSnapshots retrieves the snapshots of an account taken in [from, to], oldest first
+func (s *MemoryStore) ListBalanceSnapshots(ctx context.Context, accountID string, from, to time.Time) ([]BalanceSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []BalanceSnapshot{}
+	for _, snapshot := range s.snapshots[accountID] {
+		if !from.IsZero() && snapshot.TakenAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && snapshot.TakenAt.After(to) {
+			continue
+		}
+		result = append(result, snapshot)
+	}
+
+	return result, nil
+}
+
+// PutTransactions inserts or replaces transactions
+func (s *MemoryStore) PutTransactions(ctx context.Context, transactions []TellerTransaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, transaction := range transactions {
+		s.transactions[transaction.ID] = transaction
+	}
+
+	return nil
+}
+
+// GetTransaction retrieves a single transaction by ID
+func (s *MemoryStore) GetTransaction(ctx context.Context, id string) (*TellerTransaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transaction, ok := s.transactions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &transaction, nil
+}
+
+// ListTransactions retrieves matching transactions, newest first
+func (s *MemoryStore) ListTransactions(ctx context.Context, query StoreTransactionQuery) ([]TellerTransaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []TellerTransaction{}
+	for _, transaction := range s.transactions {
+		if query.AccountID != "" && transaction.AccountID != query.AccountID {
+			continue
+		}
+		if query.StartDate != nil && transaction.Date < *query.StartDate {
+			continue
+		}
+		if query.EndDate != nil && transaction.Date > *query.EndDate {
+			continue
+		}
+		result = append(result, transaction)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date > result[j].Date
+		}
+		return result[i].ID > result[j].ID
+	})
+
+	return result, nil
+}
+
+// RemoveTransactions deletes transactions by ID
+func (s *MemoryStore) RemoveTransactions(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.transactions, id)
+	}
+
+	return nil
+}
+
+// PutIdentities inserts or replaces identities, keyed by their account ID
+func (s *MemoryStore) PutIdentities(ctx context.Context, identities []TellerIdentity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, identity := range identities {
+		s.identities[identity.Account.ID] = identity
+	}
+
+	return nil
+}
+
+// GetIdentity retrieves the identity of an account
+func (s *MemoryStore) GetIdentity(ctx context.Context, accountID string) (*TellerIdentity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	identity, ok := s.identities[accountID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &identity, nil
+}
@@ -0,0 +1,534 @@
+package teller
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SQLDialect = string
+
+const (
+	SQLDialectSQLite   SQLDialect = "sqlite"
+	SQLDialectPostgres SQLDialect = "postgres"
+)
+
+// sqlMigrations are applied in order by SQLStore.Migrate. Never edit a
+// released migration, append a new one instead.
+var sqlMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS teller_accounts (
+		id                 TEXT PRIMARY KEY,
+		enrollment_id      TEXT NOT NULL,
+		name               TEXT NOT NULL,
+		type               TEXT NOT NULL,
+		subtype            TEXT NOT NULL,
+		status             TEXT NOT NULL,
+		currency           TEXT NOT NULL,
+		last_four          TEXT NOT NULL,
+		institution_id     TEXT NOT NULL,
+		institution_name   TEXT NOT NULL,
+		links_self         TEXT NOT NULL,
+		links_details      TEXT NOT NULL,
+		links_balances     TEXT NOT NULL,
+		links_transactions TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS teller_accounts_enrollment_id ON teller_accounts (enrollment_id)`,
+	`CREATE TABLE IF NOT EXISTS teller_balance_snapshots (
+		account_id TEXT NOT NULL,
+		taken_at   BIGINT NOT NULL,
+		ledger     TEXT NOT NULL,
+		available  TEXT NOT NULL,
+		PRIMARY KEY (account_id, taken_at)
+	)`,
+	`CREATE TABLE IF NOT EXISTS teller_transactions (
+		id                TEXT PRIMARY KEY,
+		account_id        TEXT NOT NULL,
+		amount            TEXT NOT NULL,
+		date              TEXT NOT NULL,
+		description       TEXT NOT NULL,
+		status            TEXT NOT NULL,
+		type              TEXT NOT NULL,
+		running_balance   TEXT,
+		processing_status TEXT NOT NULL,
+		category          TEXT NOT NULL,
+		counterparty_name TEXT,
+		counterparty_type TEXT NOT NULL,
+		links_self        TEXT NOT NULL,
+		links_account     TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS teller_transactions_account_date ON teller_transactions (account_id, date)`,
+	`CREATE TABLE IF NOT EXISTS teller_identities (
+		account_id TEXT PRIMARY KEY,
+		owners     TEXT NOT NULL
+	)`,
+	// raw holds the resource as JSON, keeping the unknown fields in Extra
+	// that have no column. Rows written before it are read from the columns.
+	`ALTER TABLE teller_accounts ADD COLUMN raw TEXT`,
+	`ALTER TABLE teller_transactions ADD COLUMN raw TEXT`,
+	`ALTER TABLE teller_identities ADD COLUMN extra TEXT`,
+}
+
+// SQLStore is a Store backed by database/sql. The schema works with both
+// SQLite and PostgreSQL; the caller is responsible for importing a driver
+// and opening the database.
+type SQLStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+}
+
+// NewSQLStore creates a store using an open database. Call Migrate before first use.
+func NewSQLStore(db *sql.DB, dialect SQLDialect) *SQLStore {
+	return &SQLStore{db: db, dialect: dialect}
+}
+
+// Migrate creates or upgrades the schema, applying each pending migration in
+// its own transaction. Several processes may migrate at once: each migration
+// first claims its version row, which makes the others wait and then skip it.
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS teller_schema_migrations (version INTEGER PRIMARY KEY)`)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	row := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM teller_schema_migrations`)
+	if err := row.Scan(&current); err != nil {
+		return err
+	}
+
+	for i := current; i < len(sqlMigrations); i++ {
+		err := s.withTx(ctx, func(tx *sql.Tx) error {
+			result, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO teller_schema_migrations (version) VALUES (?)
+			ON CONFLICT (version) DO NOTHING`), i+1)
+			if err != nil {
+				return err
+			}
+			if claimed, err := result.RowsAffected(); err != nil || claimed == 0 {
+				// Applied by another process since the version was read
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, sqlMigrations[i])
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PutAccounts inserts or replaces accounts
+func (s *SQLStore) PutAccounts(ctx context.Context, accounts []TellerAccount) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		return s.putAccounts(ctx, tx, accounts)
+	})
+}
+
+func (s *SQLStore) putAccounts(ctx context.Context, tx *sql.Tx, accounts []TellerAccount) error {
+	query := s.rebind(`INSERT INTO teller_accounts (
+		id, enrollment_id, name, type, subtype, status, currency, last_four,
+		institution_id, institution_name, links_self, links_details, links_balances, links_transactions, raw
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (id) DO UPDATE SET
+		enrollment_id = excluded.enrollment_id,
+		name = excluded.name,
+		type = excluded.type,
+		subtype = excluded.subtype,
+		status = excluded.status,
+		currency = excluded.currency,
+		last_four = excluded.last_four,
+		institution_id = excluded.institution_id,
+		institution_name = excluded.institution_name,
+		links_self = excluded.links_self,
+		links_details = excluded.links_details,
+		links_balances = excluded.links_balances,
+		links_transactions = excluded.links_transactions,
+		raw = excluded.raw`)
+
+	for _, a := range accounts {
+		raw, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, query,
+			a.ID, a.EnrollmentID, a.Name, a.Type, a.Subtype, a.Status, a.Currency, a.LastFour,
+			a.Institution.ID, a.Institution.Name, a.Links.Self, a.Links.Details, a.Links.Balances, a.Links.Transactions,
+			string(raw),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const sqlAccountColumns = `id, enrollment_id, name, type, subtype, status, currency, last_four,
+	institution_id, institution_name, links_self, links_details, links_balances, links_transactions, raw`
+
+func scanAccount(row interface{ Scan(...any) error }) (TellerAccount, error) {
+	var a TellerAccount
+	var raw sql.NullString
+	err := row.Scan(
+		&a.ID, &a.EnrollmentID, &a.Name, &a.Type, &a.Subtype, &a.Status, &a.Currency, &a.LastFour,
+		&a.Institution.ID, &a.Institution.Name, &a.Links.Self, &a.Links.Details, &a.Links.Balances, &a.Links.Transactions,
+		&raw,
+	)
+	if err == nil && raw.Valid {
+		err = json.Unmarshal([]byte(raw.String), &a)
+	}
+	return a, err
+}
+
+// GetAccount retrieves a single account by ID
+func (s *SQLStore) GetAccount(ctx context.Context, id string) (*TellerAccount, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT `+sqlAccountColumns+` FROM teller_accounts WHERE id = ?`), id)
+
+	account, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// ListAccounts retrieves the accounts of an enrollment, or all accounts if enrollmentID is empty
+func (s *SQLStore) ListAccounts(ctx context.Context, enrollmentID string) ([]TellerAccount, error) {
+	query := `SELECT ` + sqlAccountColumns + ` FROM teller_accounts`
+	var args []any
+	if enrollmentID != "" {
+		query += ` WHERE enrollment_id = ?`
+		args = append(args, enrollmentID)
+	}
+	query += ` ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []TellerAccount{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, account)
+	}
+
+	return result, rows.Err()
+}
+
+// RemoveAccount deletes an account and everything stored for it
+func (s *SQLStore) RemoveAccount(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, query := range []string{
+			`DELETE FROM teller_transactions WHERE account_id = ?`,
+			`DELETE FROM teller_balance_snapshots WHERE account_id = ?`,
+			`DELETE FROM teller_identities WHERE account_id = ?`,
+			`DELETE FROM teller_accounts WHERE id = ?`,
+		} {
+			if _, err := tx.ExecContext(ctx, s.rebind(query), id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PutBalanceSnapshot records a balance snapshot
+func (s *SQLStore) PutBalanceSnapshot(ctx context.Context, snapshot BalanceSnapshot) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO teller_balance_snapshots (account_id, taken_at, ledger, available)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT (account_id, taken_at) DO UPDATE SET
+		ledger = excluded.ledger,
+		available = excluded.available`),
+		snapshot.AccountID, snapshot.TakenAt.UnixNano(), snapshot.Ledger, snapshot.Available,
+	)
+	return err
+}
+
+// ListBalanceSnapshots retrieves the snapshots of an account taken in [from, to], oldest first
+func (s *SQLStore) ListBalanceSnapshots(ctx context.Context, accountID string, from, to time.Time) ([]BalanceSnapshot, error) {
+	query := `SELECT account_id, taken_at, ledger, available FROM teller_balance_snapshots WHERE account_id = ?`
+	args := []any{accountID}
+	if !from.IsZero() {
+		query += ` AND taken_at >= ?`
+		args = append(args, from.UnixNano())
+	}
+	if !to.IsZero() {
+		query += ` AND taken_at <= ?`
+		args = append(args, to.UnixNano())
+	}
+	query += ` ORDER BY taken_at`
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []BalanceSnapshot{}
+	for rows.Next() {
+		var snapshot BalanceSnapshot
+		var takenAt int64
+		if err := rows.Scan(&snapshot.AccountID, &takenAt, &snapshot.Ledger, &snapshot.Available); err != nil {
+			return nil, err
+		}
+		snapshot.TakenAt = time.Unix(0, takenAt).UTC()
+		result = append(result, snapshot)
+	}
+
+	return result, rows.Err()
+}
+
+// PutTransactions inserts or replaces transactions
+func (s *SQLStore) PutTransactions(ctx context.Context, transactions []TellerTransaction) error {
+	query := s.rebind(`INSERT INTO teller_transactions (
+		id, account_id, amount, date, description, status, type, running_balance,
+		processing_status, category, counterparty_name, counterparty_type, links_self, links_account, raw
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (id) DO UPDATE SET
+		account_id = excluded.account_id,
+		amount = excluded.amount,
+		date = excluded.date,
+		description = excluded.description,
+		status = excluded.status,
+		type = excluded.type,
+		running_balance = excluded.running_balance,
+		processing_status = excluded.processing_status,
+		category = excluded.category,
+		counterparty_name = excluded.counterparty_name,
+		counterparty_type = excluded.counterparty_type,
+		links_self = excluded.links_self,
+		links_account = excluded.links_account,
+		raw = excluded.raw`)
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, t := range transactions {
+			raw, err := json.Marshal(t)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, query,
+				t.ID, t.AccountID, t.Amount, t.Date, t.Description, t.Status, t.Type, nullString(t.RunningBalance),
+				t.Details.ProcessingStatus, t.Details.Category, nullString(t.Details.Counterparty.Name), t.Details.Counterparty.Type,
+				t.Links.Self, t.Links.Account, string(raw),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+const sqlTransactionColumns = `id, account_id, amount, date, description, status, type, running_balance,
+	processing_status, category, counterparty_name, counterparty_type, links_self, links_account, raw`
+
+func scanTransaction(row interface{ Scan(...any) error }) (TellerTransaction, error) {
+	var t TellerTransaction
+	var runningBalance, counterpartyName, raw sql.NullString
+	err := row.Scan(
+		&t.ID, &t.AccountID, &t.Amount, &t.Date, &t.Description, &t.Status, &t.Type, &runningBalance,
+		&t.Details.ProcessingStatus, &t.Details.Category, &counterpartyName, &t.Details.Counterparty.Type,
+		&t.Links.Self, &t.Links.Account, &raw,
+	)
+	t.RunningBalance = stringPtr(runningBalance)
+	t.Details.Counterparty.Name = stringPtr(counterpartyName)
+	if err == nil && raw.Valid {
+		err = json.Unmarshal([]byte(raw.String), &t)
+	}
+	return t, err
+}
+
+// GetTransaction retrieves a single transaction by ID
+func (s *SQLStore) GetTransaction(ctx context.Context, id string) (*TellerTransaction, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT `+sqlTransactionColumns+` FROM teller_transactions WHERE id = ?`), id)
+
+	transaction, err := scanTransaction(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// ListTransactions retrieves matching transactions, newest first
+func (s *SQLStore) ListTransactions(ctx context.Context, query StoreTransactionQuery) ([]TellerTransaction, error) {
+	var where []string
+	var args []any
+	if query.AccountID != "" {
+		where = append(where, `account_id = ?`)
+		args = append(args, query.AccountID)
+	}
+	if query.StartDate != nil {
+		where = append(where, `date >= ?`)
+		args = append(args, *query.StartDate)
+	}
+	if query.EndDate != nil {
+		where = append(where, `date <= ?`)
+		args = append(args, *query.EndDate)
+	}
+
+	statement := `SELECT ` + sqlTransactionColumns + ` FROM teller_transactions`
+	if len(where) > 0 {
+		statement += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	statement += ` ORDER BY date DESC, id DESC`
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(statement), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []TellerTransaction{}
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, transaction)
+	}
+
+	return result, rows.Err()
+}
+
+// RemoveTransactions deletes transactions by ID
+func (s *SQLStore) RemoveTransactions(ctx context.Context, ids []string) error {
+	query := s.rebind(`DELETE FROM teller_transactions WHERE id = ?`)
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, id := range ids {
+			if _, err := tx.ExecContext(ctx, query, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PutIdentities inserts or replaces identities, keyed by their account ID.
+// The identity's account is stored in the accounts table.
+func (s *SQLStore) PutIdentities(ctx context.Context, identities []TellerIdentity) error {
+	query := s.rebind(`INSERT INTO teller_identities (account_id, owners, extra) VALUES (?, ?, ?)
+	ON CONFLICT (account_id) DO UPDATE SET owners = excluded.owners, extra = excluded.extra`)
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, identity := range identities {
+			if err := s.putAccounts(ctx, tx, []TellerAccount{identity.Account}); err != nil {
+				return err
+			}
+
+			owners, err := json.Marshal(identity.Owners)
+			if err != nil {
+				return err
+			}
+			extra, err := json.Marshal(identity.Extra)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, query, identity.Account.ID, string(owners), string(extra)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetIdentity retrieves the identity of an account
+func (s *SQLStore) GetIdentity(ctx context.Context, accountID string) (*TellerIdentity, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT owners, extra FROM teller_identities WHERE account_id = ?`), accountID)
+
+	var owners string
+	var extra sql.NullString
+	err := row.Scan(&owners, &extra)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := TellerIdentity{Account: *account}
+	if err := json.Unmarshal([]byte(owners), &result.Owners); err != nil {
+		return nil, err
+	}
+	if extra.Valid {
+		if err := json.Unmarshal([]byte(extra.String), &result.Extra); err != nil {
+			return nil, err
+		}
+	}
+
+	return &result, nil
+}
+
+func (s *SQLStore) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rebind converts ? placeholders to the dialect's placeholder syntax
+func (s *SQLStore) rebind(query string) string {
+	if s.dialect != SQLDialectPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func stringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -0,0 +1,168 @@
+package teller
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openSQLiteStore(t *testing.T) *SQLStore {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "teller.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store := NewSQLStore(db, SQLDialectSQLite)
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestSQLStoreMigrateConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "teller.db") + "?_busy_timeout=5000"
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Go(func() {
+			db, err := sql.Open("sqlite3", path)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer db.Close()
+			errs[i] = NewSQLStore(db, SQLDialectSQLite).Migrate(context.Background())
+		})
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Migrate %d: %v", i, err)
+		}
+	}
+}
+
+func TestSQLStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := openSQLiteStore(t)
+
+	// Migrating an up-to-date schema is a no-op
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	account := TellerAccount{
+		ID:           "acc_1",
+		EnrollmentID: "enr_1",
+		Name:         "Checking",
+		Type:         TellerAccountTypeDepository,
+		Subtype:      TellerAccountSubtypeChecking,
+		Status:       TellerAccountStatusTypeOpen,
+		Currency:     "USD",
+		LastFour:     "1234",
+		Institution:  AccountInstitution{ID: "chase", Name: "Chase", Extra: map[string]json.RawMessage{"country": []byte(`"US"`)}},
+		Links: AccountLinks{
+			Self:         "https://api.teller.io/accounts/acc_1",
+			Details:      "https://api.teller.io/accounts/acc_1/details",
+			Balances:     "https://api.teller.io/accounts/acc_1/balances",
+			Transactions: "https://api.teller.io/accounts/acc_1/transactions",
+		},
+		// Fields without a column survive the round trip
+		Extra: map[string]json.RawMessage{"nickname": []byte(`"Bills"`), "limits": []byte(`{"daily":500}`)},
+	}
+
+	balance := "100.00"
+	counterparty := "ACME"
+	transaction := TellerTransaction{
+		ID:             "txn_1",
+		AccountID:      "acc_1",
+		Amount:         "-12.34",
+		Date:           "2026-05-01",
+		Description:    "ACME STORE",
+		Status:         TellerTransactionStatusTypePosted,
+		Type:           "card_payment",
+		RunningBalance: &balance,
+		Details: TransactionDetails{
+			ProcessingStatus: TellerTransactionProcessingTypeComplete,
+			Category:         "shopping",
+			Extra:            map[string]json.RawMessage{"merchant_id": []byte(`"m_1"`)},
+			Counterparty:     Counterparty{Name: &counterparty, Type: TellerTransactionCounterPartyTypeOrganization},
+		},
+		Links: AccountResourceLinks{Self: "https://api.teller.io/accounts/acc_1/transactions/txn_1", Account: "https://api.teller.io/accounts/acc_1"},
+		Extra: map[string]json.RawMessage{"memo": []byte(`"lunch"`)},
+	}
+
+	identity := TellerIdentity{
+		Account: account,
+		Owners: []TellerOwner{{
+			Type:   "person",
+			Names:  []OwnerName{{Type: "name", Data: "Jane Doe"}},
+			Emails: []Email{{Data: "jane@example.com"}},
+		}},
+		Extra: map[string]json.RawMessage{"verified_at": []byte(`"2026-05-01"`)},
+	}
+
+	if err := store.PutAccounts(ctx, []TellerAccount{account}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutTransactions(ctx, []TellerTransaction{transaction}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutIdentities(ctx, []TellerIdentity{identity}); err != nil {
+		t.Fatal(err)
+	}
+
+	gotAccount, err := store.GetAccount(ctx, "acc_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*gotAccount, account) {
+		t.Errorf("GetAccount = %+v, want %+v", *gotAccount, account)
+	}
+
+	gotTransaction, err := store.GetTransaction(ctx, "txn_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*gotTransaction, transaction) {
+		t.Errorf("GetTransaction = %+v, want %+v", *gotTransaction, transaction)
+	}
+
+	gotIdentity, err := store.GetIdentity(ctx, "acc_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*gotIdentity, identity) {
+		t.Errorf("GetIdentity = %+v, want %+v", *gotIdentity, identity)
+	}
+
+	// Rows written before the raw column are read from the other columns
+	if _, err := store.db.ExecContext(ctx, `UPDATE teller_accounts SET raw = NULL`); err != nil {
+		t.Fatal(err)
+	}
+	gotAccount, err = store.GetAccount(ctx, "acc_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	columnsOnly := account
+	columnsOnly.Extra, columnsOnly.Institution.Extra = nil, nil
+	if !reflect.DeepEqual(*gotAccount, columnsOnly) {
+		t.Errorf("GetAccount without raw = %+v, want %+v", *gotAccount, columnsOnly)
+	}
+
+	if _, err := store.GetAccount(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAccount(missing) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,390 @@
+package teller
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// encryptedTokenVersion prefixes encoded tokens
+const encryptedTokenVersion = "v1"
+
+// KeyEncryptionKey wraps the per-token data encryption keys. Implementations
+// may keep the key locally (see AESKeyEncryptionKey) or delegate to a KMS.
+type KeyEncryptionKey interface {
+	// ID identifies the key so that tokens can be decrypted after rotation
+	ID() string
+	Wrap(dek []byte) ([]byte, error)
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+// AESKeyEncryptionKey is a KeyEncryptionKey that wraps keys with AES-GCM
+type AESKeyEncryptionKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewAESKeyEncryptionKey creates a key encryption key from a 16, 24 or 32 byte AES key
+func NewAESKeyEncryptionKey(id string, key []byte) (*AESKeyEncryptionKey, error) {
+	if id == "" {
+		return nil, errors.New("key id must be non-empty")
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AESKeyEncryptionKey{id: id, aead: aead}, nil
+}
+
+// ID returns the key's identifier
+func (k *AESKeyEncryptionKey) ID() string {
+	return k.id
+}
+
+// Wrap encrypts a data encryption key
+func (k *AESKeyEncryptionKey) Wrap(dek []byte) ([]byte, error) {
+	return sealAEAD(k.aead, dek, []byte(k.id))
+}
+
+// Unwrap decrypts a data encryption key
+func (k *AESKeyEncryptionKey) Unwrap(wrapped []byte) ([]byte, error) {
+	return openAEAD(k.aead, wrapped, []byte(k.id))
+}
+
+// EncryptedToken is an access token encrypted with its own data encryption
+// key, which is in turn wrapped by the key encryption key KeyID
+type EncryptedToken struct {
+	KeyID      string
+	WrappedKey []byte
+	Ciphertext []byte // nonce followed by the sealed token
+}
+
+// MarshalText encodes the token as a single string suitable for a database column
+func (t EncryptedToken) MarshalText() ([]byte, error) {
+	return []byte(strings.Join([]string{
+		encryptedTokenVersion,
+		base64.RawURLEncoding.EncodeToString([]byte(t.KeyID)),
+		base64.RawURLEncoding.EncodeToString(t.WrappedKey),
+		base64.RawURLEncoding.EncodeToString(t.Ciphertext),
+	}, ".")), nil
+}
+
+// UnmarshalText decodes a token encoded by MarshalText
+func (t *EncryptedToken) UnmarshalText(text []byte) error {
+	parts := strings.Split(string(text), ".")
+	if len(parts) != 4 || parts[0] != encryptedTokenVersion {
+		return errors.New("invalid encrypted token")
+	}
+
+	keyID, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return errors.New("invalid encrypted token")
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errors.New("invalid encrypted token")
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return errors.New("invalid encrypted token")
+	}
+
+	*t = EncryptedToken{KeyID: string(keyID), WrappedKey: wrapped, Ciphertext: ciphertext}
+	return nil
+}
+
+func (t *EncryptedToken) equal(other *EncryptedToken) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	return t.KeyID == other.KeyID && bytes.Equal(t.WrappedKey, other.WrappedKey) && bytes.Equal(t.Ciphertext, other.Ciphertext)
+}
+
+// TokenCipher encrypts access tokens with a primary key encryption key and
+// decrypts tokens encrypted with the primary or any previous key
+type TokenCipher struct {
+	primary KeyEncryptionKey
+	keys    map[string]KeyEncryptionKey
+}
+
+// NewTokenCipher creates a cipher encrypting with primary. Keys that were
+// rotated out must be passed as previous until all tokens are re-encrypted.
+func NewTokenCipher(primary KeyEncryptionKey, previous ...KeyEncryptionKey) *TokenCipher {
+	keys := map[string]KeyEncryptionKey{primary.ID(): primary}
+	for _, key := range previous {
+		keys[key.ID()] = key
+	}
+
+	return &TokenCipher{primary: primary, keys: keys}
+}
+
+// Encrypt encrypts the access token of a user. The user ID is bound to the
+// ciphertext, so a token cannot be decrypted as another user's.
+func (c *TokenCipher) Encrypt(userID, token string) (*EncryptedToken, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := sealAEAD(aead, []byte(token), []byte(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := c.primary.Wrap(dek)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedToken{KeyID: c.primary.ID(), WrappedKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+// Decrypt decrypts the access token of a user
+func (c *TokenCipher) Decrypt(userID string, token *EncryptedToken) (string, error) {
+	kek, ok := c.keys[token.KeyID]
+	if !ok {
+		return "", fmt.Errorf("unknown key encryption key %q", token.KeyID)
+	}
+
+	dek, err := kek.Unwrap(token.WrappedKey)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newGCM(dek)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := openAEAD(aead, token.Ciphertext, []byte(userID))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a token is encrypted with a key other than the primary one
+func (c *TokenCipher) NeedsRotation(token *EncryptedToken) bool {
+	return token.KeyID != c.primary.ID()
+}
+
+// SealedTokenStore persists encrypted access tokens, e.g. in a column next to the user record
+type SealedTokenStore interface {
+	// Get returns the encrypted token of a user, ErrTokenNotFound if there is none,
+	// or ErrTokenInvalid if it was marked invalid
+	Get(ctx context.Context, userID string) (*EncryptedToken, error)
+	Put(ctx context.Context, userID string, token *EncryptedToken) error
+	// Swap replaces the token of a user with new only if it is still old,
+	// keeping any invalid mark, and reports whether it did
+	Swap(ctx context.Context, userID string, old, new *EncryptedToken) (bool, error)
+	MarkInvalid(ctx context.Context, userID string, reason string) error
+	// UserIDs lists the users with a stored token, for re-encryption
+	UserIDs(ctx context.Context) ([]string, error)
+}
+
+// EncryptedTokenStore is a TokenStore that keeps tokens encrypted at rest.
+// Tokens are only decrypted when the client resolves them to build a request.
+type EncryptedTokenStore struct {
+	sealed SealedTokenStore
+	cipher *TokenCipher
+}
+
+// NewEncryptedTokenStore creates a token store encrypting tokens into sealed
+func NewEncryptedTokenStore(sealed SealedTokenStore, cipher *TokenCipher) *EncryptedTokenStore {
+	return &EncryptedTokenStore{sealed: sealed, cipher: cipher}
+}
+
+// Set encrypts and stores the access token of a user
+func (s *EncryptedTokenStore) Set(ctx context.Context, userID, token string) error {
+	encrypted, err := s.cipher.Encrypt(userID, token)
+	if err != nil {
+		return err
+	}
+
+	return s.sealed.Put(ctx, userID, encrypted)
+}
+
+// Token returns the decrypted access token of a user
+func (s *EncryptedTokenStore) Token(ctx context.Context, userID string) (string, error) {
+	encrypted, err := s.sealed.Get(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.cipher.Decrypt(userID, encrypted)
+}
+
+// MarkInvalid flags the user's token as no longer usable
+func (s *EncryptedTokenStore) MarkInvalid(ctx context.Context, userID string, reason string) error {
+	return s.sealed.MarkInvalid(ctx, userID, reason)
+}
+
+// Reencrypt re-encrypts every token not encrypted with the cipher's primary
+// key and returns how many were updated. Invalid tokens are left untouched,
+// as are tokens replaced or marked invalid while being re-encrypted.
+func (s *EncryptedTokenStore) Reencrypt(ctx context.Context) (int, error) {
+	userIDs, err := s.sealed.UserIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, userID := range userIDs {
+		encrypted, err := s.sealed.Get(ctx, userID)
+		if errors.Is(err, ErrTokenInvalid) || errors.Is(err, ErrTokenNotFound) {
+			continue
+		}
+		if err != nil {
+			return updated, err
+		}
+		if !s.cipher.NeedsRotation(encrypted) {
+			continue
+		}
+
+		token, err := s.cipher.Decrypt(userID, encrypted)
+		if err != nil {
+			return updated, fmt.Errorf("decrypt token of %s: %w", userID, err)
+		}
+		reencrypted, err := s.cipher.Encrypt(userID, token)
+		if err != nil {
+			return updated, err
+		}
+
+		swapped, err := s.sealed.Swap(ctx, userID, encrypted, reencrypted)
+		if err != nil {
+			return updated, err
+		}
+		if swapped {
+			updated++
+		}
+	}
+
+	return updated, nil
+}
+
+type sealedToken struct {
+	token   *EncryptedToken
+	invalid string
+}
+
+// MemorySealedTokenStore is a SealedTokenStore that keeps encrypted tokens in memory.
+// It is safe for concurrent use.
+type MemorySealedTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]sealedToken
+}
+
+// NewMemorySealedTokenStore creates an empty in-memory sealed token store
+func NewMemorySealedTokenStore() *MemorySealedTokenStore {
+	return &MemorySealedTokenStore{tokens: map[string]sealedToken{}}
+}
+
+// Get returns the encrypted token of a user
+func (s *MemorySealedTokenStore) Get(ctx context.Context, userID string) (*EncryptedToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.tokens[userID]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	if entry.invalid != "" {
+		return nil, ErrTokenInvalid
+	}
+
+	return entry.token, nil
+}
+
+// Put stores the encrypted token of a user, clearing any invalid mark
+func (s *MemorySealedTokenStore) Put(ctx context.Context, userID string, token *EncryptedToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[userID] = sealedToken{token: token}
+	return nil
+}
+
+// Swap replaces the encrypted token of a user if it is still old, keeping any invalid mark
+func (s *MemorySealedTokenStore) Swap(ctx context.Context, userID string, old, new *EncryptedToken) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.tokens[userID]
+	if !ok || !entry.token.equal(old) {
+		return false, nil
+	}
+
+	entry.token = new
+	s.tokens[userID] = entry
+	return true, nil
+}
+
+// MarkInvalid flags the user's token as no longer usable
+func (s *MemorySealedTokenStore) MarkInvalid(ctx context.Context, userID string, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.tokens[userID]
+	if !ok {
+		return ErrTokenNotFound
+	}
+
+	entry.invalid = reason
+	s.tokens[userID] = entry
+	return nil
+}
+
+// UserIDs lists the users with a stored token
+func (s *MemorySealedTokenStore) UserIDs(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]string, 0, len(s.tokens))
+	for userID := range s.tokens {
+		result = append(result, userID)
+	}
+
+	return result, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealAEAD encrypts plaintext under a random nonce and prepends the nonce
+func sealAEAD(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func openAEAD(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, additionalData)
+}
@@ -0,0 +1,136 @@
+package teller
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newTestKey(t *testing.T, id string, b byte) *AESKeyEncryptionKey {
+	t.Helper()
+
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = b
+	}
+
+	kek, err := NewAESKeyEncryptionKey(id, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kek
+}
+
+func TestEncryptedTokenText(t *testing.T) {
+	tests := []struct {
+		name  string
+		keyID string
+	}{
+		{"simple", "primary"},
+		{"dotted", "projects/p/keys/k.v2"},
+		{"unicode", "clé"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := EncryptedToken{KeyID: tt.keyID, WrappedKey: []byte{1, 2, 3}, Ciphertext: []byte{4, 5, 6, 7}}
+			text, err := token.MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var decoded EncryptedToken
+			if err := decoded.UnmarshalText(text); err != nil {
+				t.Fatalf("UnmarshalText(%s): %v", text, err)
+			}
+			if !decoded.equal(&token) {
+				t.Errorf("UnmarshalText(%s) = %+v, want %+v", text, decoded, token)
+			}
+		})
+	}
+
+	for _, text := range []string{"", "v1.a.b", "v9.cHJpbWFyeQ.AQID.BAUG", "v1.!!.AQID.BAUG", "v1.cHJpbWFyeQ.AQID.BAUG.x"} {
+		var decoded EncryptedToken
+		if err := decoded.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+func TestTokenCipher(t *testing.T) {
+	oldKey := newTestKey(t, "old", 1)
+	newKey := newTestKey(t, "new", 2)
+
+	encrypted, err := NewTokenCipher(oldKey).Encrypt("user_1", "token_abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := NewTokenCipher(newKey, oldKey)
+	tests := []struct {
+		name    string
+		cipher  *TokenCipher
+		userID  string
+		want    string
+		wantErr bool
+	}{
+		{"same key", NewTokenCipher(oldKey), "user_1", "token_abc", false},
+		{"previous key", rotated, "user_1", "token_abc", false},
+		{"other user", rotated, "user_2", "", true},
+		{"unknown key", NewTokenCipher(newKey), "user_1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cipher.Decrypt(tt.userID, encrypted)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Decrypt = %q, %v, want %q, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	if !rotated.NeedsRotation(encrypted) {
+		t.Error("NeedsRotation = false for a token encrypted with a previous key")
+	}
+}
+
+func TestEncryptedTokenStoreReencrypt(t *testing.T) {
+	ctx := context.Background()
+	oldKey := newTestKey(t, "old", 1)
+	newKey := newTestKey(t, "new", 2)
+
+	sealed := NewMemorySealedTokenStore()
+	before := NewEncryptedTokenStore(sealed, NewTokenCipher(oldKey))
+	for userID, token := range map[string]string{"user_1": "token_1", "user_2": "token_2", "user_3": "token_3"} {
+		if err := before.Set(ctx, userID, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := before.MarkInvalid(ctx, "user_3", "enrollment.disconnected"); err != nil {
+		t.Fatal(err)
+	}
+
+	after := NewEncryptedTokenStore(sealed, NewTokenCipher(newKey, oldKey))
+	updated, err := after.Reencrypt(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != 2 {
+		t.Errorf("Reencrypt updated %d tokens, want 2", updated)
+	}
+
+	// Re-encrypted tokens decrypt without the previous key
+	current := NewEncryptedTokenStore(sealed, NewTokenCipher(newKey))
+	for userID, want := range map[string]string{"user_1": "token_1", "user_2": "token_2"} {
+		if got, err := current.Token(ctx, userID); err != nil || got != want {
+			t.Errorf("Token(%s) = %q, %v, want %q", userID, got, err, want)
+		}
+	}
+	if _, err := current.Token(ctx, "user_3"); !errors.Is(err, ErrTokenInvalid) {
+		t.Errorf("Token(user_3) error = %v, want ErrTokenInvalid", err)
+	}
+
+	if updated, err := after.Reencrypt(ctx); err != nil || updated != 0 {
+		t.Errorf("second Reencrypt = %d, %v, want 0, nil", updated, err)
+	}
+}
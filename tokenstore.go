@@ -0,0 +1,144 @@
+package teller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrTokenNotFound is returned by a TokenStore that has no access token for a user
+var ErrTokenNotFound = errors.New("access token not found")
+
+// ErrTokenInvalid is returned by a TokenStore for a token that was marked invalid
+var ErrTokenInvalid = errors.New("access token marked invalid")
+
+// TokenStore resolves the access tokens of users, so that call sites only deal with user IDs
+type TokenStore interface {
+	// Token returns the access token of a user, ErrTokenNotFound if there is none,
+	// or ErrTokenInvalid if it was marked invalid
+	Token(ctx context.Context, userID string) (string, error)
+	// MarkInvalid flags the user's token as no longer usable, reason is the API error code
+	MarkInvalid(ctx context.Context, userID string, reason string) error
+}
+
+// IsEnrollmentDisconnected reports whether err is an API error caused by a disconnected enrollment
+func IsEnrollmentDisconnected(err error) bool {
+	var tellerErr *TellerError
+	if !errors.As(err, &tellerErr) {
+		return false
+	}
+	return strings.HasPrefix(tellerErr.Code, "enrollment.disconnected")
+}
+
+type memoryToken struct {
+	token   string
+	invalid string
+}
+
+// MemoryTokenStore is a TokenStore that keeps tokens in memory.
+// It is safe for concurrent use.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]memoryToken
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: map[string]memoryToken{}}
+}
+
+// Set stores the access token of a user, clearing any invalid mark
+func (s *MemoryTokenStore) Set(userID, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[userID] = memoryToken{token: token}
+}
+
+// Remove deletes the access token of a user
+func (s *MemoryTokenStore) Remove(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, userID)
+}
+
+// Token returns the access token of a user
+func (s *MemoryTokenStore) Token(ctx context.Context, userID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.tokens[userID]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	if entry.invalid != "" {
+		return "", ErrTokenInvalid
+	}
+
+	return entry.token, nil
+}
+
+// MarkInvalid flags the user's token as no longer usable
+func (s *MemoryTokenStore) MarkInvalid(ctx context.Context, userID string, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.tokens[userID]
+	if !ok {
+		return ErrTokenNotFound
+	}
+
+	entry.invalid = reason
+	s.tokens[userID] = entry
+
+	return nil
+}
+
+// InvalidReason returns why the user's token was marked invalid, or "" if it is valid
+func (s *MemoryTokenStore) InvalidReason(userID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tokens[userID].invalid
+}
+
+// ForToken returns a view of the client that authenticates every request with token.
+// The view shares the client's connection pool and rate limiter.
+func (c *Client) ForToken(token string) *Client {
+	clone := *c
+	clone.accessToken = token
+	clone.userID = ""
+	clone.initModules()
+	return &clone
+}
+
+// ForUser returns a view of the client that resolves the access token of
+// userID from the client's TokenStore for every request, and marks it
+// invalid when the API reports the enrollment as disconnected.
+// A per-request AccessToken option still takes precedence.
+func (c *Client) ForUser(userID string) *Client {
+	clone := *c
+	clone.accessToken = ""
+	clone.userID = userID
+	clone.initModules()
+	return &clone
+}
+
+// resolveToken returns override if set, otherwise the token of the client's
+// user if it is scoped to one, otherwise the client's own token
+func (c *Client) resolveToken(ctx context.Context, override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+
+	if c.userID != "" {
+		if c.tokens == nil {
+			return "", errors.New("client has no token store")
+		}
+		return c.tokens.Token(ctx, c.userID)
+	}
+
+	return c.accessToken, nil
+}
@@ -1,56 +1,153 @@
 package teller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 	"strconv"
 )
 
-type TellerTransactionProcessingType = string
+type TellerTransactionProcessingType string
 
 const (
 	TellerTransactionProcessingTypePending  TellerTransactionProcessingType = "pending"
 	TellerTransactionProcessingTypeComplete TellerTransactionProcessingType = "complete"
 )
 
-type TellerTransactionCounterPartyType = string
+// IsKnown reports whether t is a processing status this version of the library knows.
+// Teller may add values, which decode without error but are not known.
+func (t TellerTransactionProcessingType) IsKnown() bool {
+	switch t {
+	case TellerTransactionProcessingTypePending, TellerTransactionProcessingTypeComplete:
+		return true
+	}
+	return false
+}
+
+type TellerTransactionCounterPartyType string
 
 const (
 	TellerTransactionCounterPartyTypePerson       TellerTransactionCounterPartyType = "person"
 	TellerTransactionCounterPartyTypeOrganization TellerTransactionCounterPartyType = "organization"
 )
 
-type TellerTransactionStatusType = string
+// IsKnown reports whether t is a counterparty type this version of the library knows.
+// Teller may add values, which decode without error but are not known.
+func (t TellerTransactionCounterPartyType) IsKnown() bool {
+	switch t {
+	case TellerTransactionCounterPartyTypePerson, TellerTransactionCounterPartyTypeOrganization:
+		return true
+	}
+	return false
+}
+
+type TellerTransactionStatusType string
 
 const (
 	TellerTransactionStatusTypePosted  TellerTransactionStatusType = "posted"
 	TellerTransactionStatusTypePending TellerTransactionStatusType = "pending"
 )
 
+// IsKnown reports whether s is a transaction status this version of the library knows.
+// Teller may add values, which decode without error but are not known.
+func (s TellerTransactionStatusType) IsKnown() bool {
+	switch s {
+	case TellerTransactionStatusTypePosted, TellerTransactionStatusTypePending:
+		return true
+	}
+	return false
+}
+
+// Counterparty is the other party of a transaction
+type Counterparty struct {
+	Name *string                           `json:"name"`
+	Type TellerTransactionCounterPartyType `json:"type"` // "person" or "organization"
+	// Extra holds counterparty fields this version of the library does not know
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// TransactionDetails holds the enriched information of a transaction
+type TransactionDetails struct {
+	ProcessingStatus TellerTransactionProcessingType `json:"processing_status"` // "pending" or "complete"
+	Category         TransactionCategory             `json:"category"`
+	Counterparty     Counterparty                    `json:"counterparty"`
+	// Extra holds detail fields added to the API after this version of the library
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
 // TellerTransaction represents a financial transaction
 type TellerTransaction struct {
-	AccountID   string `json:"account_id"`
-	Amount      string `json:"amount"`
-	Date        string `json:"date"`
-	Description string `json:"description"`
-	Details     struct {
-		ProcessingStatus TellerTransactionProcessingType `json:"processing_status"` // "pending" or "complete"
-		Category         string                          `json:"category"`
-		Counterparty     struct {
-			Name *string                           `json:"name"`
-			Type TellerTransactionCounterPartyType `json:"type"` // "person" or "organization"
-		} `json:"counterparty"`
-	} `json:"details"`
-	Status TellerTransactionStatusType `json:"status"` // "posted" or "pending"
-	ID     string                      `json:"id"`
-	Links  struct {
-		Self    string `json:"self"`
-		Account string `json:"account"`
-	} `json:"links"`
-	RunningBalance *string `json:"running_balance"`
-	Type           string  `json:"type"`
+	AccountID      string                      `json:"account_id"`
+	Amount         string                      `json:"amount"`
+	Date           string                      `json:"date"`
+	Description    string                      `json:"description"`
+	Details        TransactionDetails          `json:"details"`
+	Status         TellerTransactionStatusType `json:"status"` // "posted" or "pending"
+	ID             string                      `json:"id"`
+	Links          AccountResourceLinks        `json:"links"`
+	RunningBalance *string                     `json:"running_balance"`
+	Type           string                      `json:"type"`
+	// Extra holds transaction fields added to the API after this version of the library;
+	// they are kept when the transaction is encoded again or saved to a Store
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the transaction, keeping unknown fields in Extra
+func (t *TellerTransaction) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, t)
+}
+
+// MarshalJSON encodes the transaction with the unknown fields kept in Extra
+func (t TellerTransaction) MarshalJSON() ([]byte, error) {
+	return marshalResource(t)
+}
+
+// Validate returns a *SchemaError if the transaction has unknown fields or enum values
+func (t TellerTransaction) Validate() error {
+	return checkSchema("", t)
+}
+
+func (t TellerTransaction) schemaProblems(path string) []string {
+	problems := unknownFieldProblems(path, t.Extra)
+	problems = append(problems, unknownValueProblem(fieldPath(path, "status"), t.Status, t.Status.IsKnown())...)
+	problems = append(problems, t.Details.schemaProblems(fieldPath(path, "details"))...)
+	problems = append(problems, t.Links.schemaProblems(fieldPath(path, "links"))...)
+	return problems
+}
+
+// UnmarshalJSON decodes the details, keeping unknown fields in Extra
+func (d *TransactionDetails) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, d)
+}
+
+// MarshalJSON encodes the details with the unknown fields kept in Extra
+func (d TransactionDetails) MarshalJSON() ([]byte, error) {
+	return marshalResource(d)
+}
+
+func (d TransactionDetails) schemaProblems(path string) []string {
+	problems := unknownFieldProblems(path, d.Extra)
+	problems = append(problems, unknownValueProblem(fieldPath(path, "processing_status"), d.ProcessingStatus, d.ProcessingStatus.IsKnown())...)
+	problems = append(problems, unknownValueProblem(fieldPath(path, "category"), d.Category, d.Category.IsKnown())...)
+	problems = append(problems, d.Counterparty.schemaProblems(fieldPath(path, "counterparty"))...)
+	return problems
+}
+
+// UnmarshalJSON decodes the counterparty, keeping unknown fields in Extra
+func (c *Counterparty) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, c)
+}
+
+// MarshalJSON encodes the counterparty with the unknown fields kept in Extra
+func (c Counterparty) MarshalJSON() ([]byte, error) {
+	return marshalResource(c)
+}
+
+func (c Counterparty) schemaProblems(path string) []string {
+	problems := unknownFieldProblems(path, c.Extra)
+	problems = append(problems, unknownValueProblem(fieldPath(path, "type"), c.Type, c.Type.IsKnown())...)
+	return problems
 }
 
 // TransactionModule handles transaction-related API calls
@@ -60,47 +157,18 @@ type TransactionModule struct {
 
 // List retrieves transactions for an account
 func (m *TransactionModule) List(accountID string, options *TellerOptionsPagination) ([]TellerTransaction, error) {
-	endpoint := fmt.Sprintf("%s/accounts/%s/transactions", m.client.baseURL, accountID)
+	return m.ListContext(context.Background(), accountID, options)
+}
 
-	// Add pagination parameters if provided
+// ListContext is List with a context, which cancels the request and any wait for the rate limiter
+func (m *TransactionModule) ListContext(ctx context.Context, accountID string, options *TellerOptionsPagination) ([]TellerTransaction, error) {
+	var base *TellerOptionsBase
 	if options != nil {
-		params := url.Values{}
-		if options.Cursor != nil {
-			params.Set("from_id", *options.Cursor)
-		}
-		if options.Limit != nil && *options.Limit > 0 {
-			params.Set("count", strconv.Itoa(*options.Limit))
-		}
-		if options.StartDate != nil {
-			params.Set("start_date", *options.StartDate)
-		}
-		if options.EndDate != nil {
-			params.Set("end_date", *options.EndDate)
-		}
-		if len(params) > 0 {
-			endpoint += "?" + params.Encode()
-		}
+		base = &options.TellerOptionsBase
 	}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if options != nil && options.AccessToken != "" {
-		req.SetBasicAuth(options.AccessToken, "")
-	} else if m.client.accessToken != "" {
-		req.SetBasicAuth(m.client.accessToken, "")
-	}
-
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var result []TellerTransaction
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := m.client.do(ctx, "GET", EndpointTransactions, transactionsPath(accountID, options), m.client.token(base), &result); err != nil {
 		return nil, err
 	}
 
@@ -109,29 +177,42 @@ func (m *TransactionModule) List(accountID string, options *TellerOptionsPaginat
 
 // Get retrieves a single transaction
 func (m *TransactionModule) Get(accountID string, id string, options *TellerOptionsBase) (*TellerTransaction, error) {
-	endpoint := fmt.Sprintf("%s/accounts/%s/transactions/%s", m.client.baseURL, accountID, id)
+	return m.GetContext(context.Background(), accountID, id, options)
+}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
-	if err != nil {
+// GetContext is Get with a context
+func (m *TransactionModule) GetContext(ctx context.Context, accountID string, id string, options *TellerOptionsBase) (*TellerTransaction, error) {
+	var result TellerTransaction
+	if err := m.client.do(ctx, "GET", EndpointTransaction, fmt.Sprintf("/accounts/%s/transactions/%s", accountID, id), m.client.token(options), &result); err != nil {
 		return nil, err
 	}
 
-	if options != nil && options.AccessToken != "" {
-		req.SetBasicAuth(options.AccessToken, "")
-	} else if m.client.accessToken != "" {
-		req.SetBasicAuth(m.client.accessToken, "")
-	}
+	return &result, nil
+}
 
-	resp, err := m.client.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// transactionsPath builds the transactions path of an account with its pagination parameters
+func transactionsPath(accountID string, options *TellerOptionsPagination) string {
+	path := fmt.Sprintf("/accounts/%s/transactions", accountID)
 
-	var result TellerTransaction
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+	// Add pagination parameters if provided
+	if options != nil {
+		params := url.Values{}
+		if options.Cursor != nil {
+			params.Set("from_id", *options.Cursor)
+		}
+		if options.Limit != nil && *options.Limit > 0 {
+			params.Set("count", strconv.Itoa(*options.Limit))
+		}
+		if options.StartDate != nil {
+			params.Set("start_date", *options.StartDate)
+		}
+		if options.EndDate != nil {
+			params.Set("end_date", *options.EndDate)
+		}
+		if len(params) > 0 {
+			path += "?" + params.Encode()
+		}
 	}
 
-	return &result, nil
+	return path
 }
@@ -0,0 +1,221 @@
+package teller
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// transferPattern matches descriptions that name a transfer or a card payment
+var transferPattern = regexp.MustCompile(`\b(TRANSFER|XFER|TFR|PAYMENT|PMT|AUTOPAY|AUTO PAY|THANK YOU|ONLINE BANKING|TO CARD|FROM CHK|FROM SAV)\b`)
+
+// TransferOptions tunes MatchTransfers
+type TransferOptions struct {
+	// Window is how many days apart the two legs may be posted, defaults to 3
+	Window int
+	// SameEnrollment only pairs transactions of accounts of the same enrollment.
+	// By default any two of the given accounts may be paired, as they belong to one user.
+	SameEnrollment bool
+}
+
+// Transfer is a movement of money between two of a user's accounts
+type Transfer struct {
+	// From is the leg of the account the money left, To the leg of the account it went to
+	From TellerTransaction
+	To   TellerTransaction
+	// Amount is the amount moved, positive
+	Amount Amount
+	// CardPayment is set when money moved from a depository account to a credit account
+	CardPayment bool
+	// DaysApart is how many days separate the two legs
+	DaysApart int
+}
+
+type transferLeg struct {
+	transaction TellerTransaction
+	account     TellerAccount
+	date        time.Time
+	amount      Amount
+	hinted      bool
+}
+
+// MatchTransfers pairs transactions of different accounts that move the
+// same amount in opposite directions within a few days, such as a transfer
+// from checking to savings or a credit card payment.
+//
+// Depository accounts report money leaving as a negative amount. Credit
+// accounts are reported either way by institutions, so a credit leg is
+// paired on its absolute amount and the direction taken from the depository
+// leg. A credit leg with the same sign as the depository leg could as well
+// be two purchases, and two depository legs could as well be a paycheck and
+// a bill of the same amount, so such pairs are only kept when a description
+// mentions a transfer or the other account's last four digits. Accounts of
+// different currencies are never paired. Each transaction is part of at most
+// one transfer; when several pair up, the legs with such descriptions, then
+// the closest in time, win.
+func MatchTransfers(accounts []TellerAccount, transactions []TellerTransaction, options *TransferOptions) []Transfer {
+	if options == nil {
+		options = &TransferOptions{}
+	}
+
+	window := options.Window
+	if window <= 0 {
+		window = 3
+	}
+
+	byID := map[string]TellerAccount{}
+	for _, account := range accounts {
+		byID[account.ID] = account
+	}
+
+	// Legs are bucketed by absolute amount, only equal amounts can pair
+	buckets := map[Amount][]transferLeg{}
+	for _, transaction := range transactions {
+		account, ok := byID[transaction.AccountID]
+		if !ok {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, transaction.Date)
+		if err != nil {
+			continue
+		}
+		amount, err := ParseAmount(transaction.Amount)
+		if err != nil || amount == 0 {
+			continue
+		}
+
+		buckets[amount.Abs()] = append(buckets[amount.Abs()], transferLeg{
+			transaction: transaction,
+			account:     account,
+			date:        date,
+			amount:      amount,
+			hinted:      transferPattern.MatchString(strings.ToUpper(transaction.Description)),
+		})
+	}
+
+	type candidate struct {
+		transfer Transfer
+		score    int
+	}
+
+	var candidates []candidate
+	for _, legs := range buckets {
+		for i := range legs {
+			for j := i + 1; j < len(legs); j++ {
+				a, b := legs[i], legs[j]
+				if a.account.ID == b.account.ID || a.account.Currency != b.account.Currency ||
+					options.SameEnrollment && a.account.EnrollmentID != b.account.EnrollmentID {
+					continue
+				}
+
+				days := int(a.date.Sub(b.date).Hours() / 24)
+				if days < 0 {
+					days = -days
+				}
+				if days > window {
+					continue
+				}
+
+				transfer, ok := pairTransfer(a, b)
+				if !ok {
+					continue
+				}
+				transfer.DaysApart = days
+
+				score := 0
+				for _, leg := range []struct{ self, other transferLeg }{{a, b}, {b, a}} {
+					if leg.self.hinted {
+						score += 2
+					}
+					if lastFour := leg.other.account.LastFour; lastFour != "" && strings.Contains(leg.self.transaction.Description, lastFour) {
+						score += 3
+					}
+				}
+
+				aCredit, bCredit := a.account.Type == TellerAccountTypeCredit, b.account.Type == TellerAccountTypeCredit
+				ambiguous := !aCredit && !bCredit || aCredit != bCredit && (a.amount < 0) == (b.amount < 0)
+				if ambiguous && score == 0 {
+					continue
+				}
+
+				candidates = append(candidates, candidate{transfer: transfer, score: score})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		if candidates[i].transfer.DaysApart != candidates[j].transfer.DaysApart {
+			return candidates[i].transfer.DaysApart < candidates[j].transfer.DaysApart
+		}
+		if candidates[i].transfer.From.Date != candidates[j].transfer.From.Date {
+			return candidates[i].transfer.From.Date < candidates[j].transfer.From.Date
+		}
+		return candidates[i].transfer.From.ID < candidates[j].transfer.From.ID
+	})
+
+	used := map[string]bool{}
+	var transfers []Transfer
+	for _, c := range candidates {
+		if used[c.transfer.From.ID] || used[c.transfer.To.ID] {
+			continue
+		}
+		used[c.transfer.From.ID] = true
+		used[c.transfer.To.ID] = true
+		transfers = append(transfers, c.transfer)
+	}
+
+	sort.SliceStable(transfers, func(i, j int) bool {
+		return transfers[i].From.Date < transfers[j].From.Date
+	})
+
+	return transfers
+}
+
+// pairTransfer orders two legs of equal absolute amount into a transfer, if their signs allow it
+func pairTransfer(a, b transferLeg) (Transfer, bool) {
+	aCredit := a.account.Type == TellerAccountTypeCredit
+	bCredit := b.account.Type == TellerAccountTypeCredit
+
+	var from, to transferLeg
+	switch {
+	case aCredit != bCredit:
+		// The depository leg gives the direction, the credit leg may have either sign
+		deposit, card := a, b
+		if aCredit {
+			deposit, card = b, a
+		}
+		if deposit.amount < 0 {
+			from, to = deposit, card
+		} else {
+			from, to = card, deposit
+		}
+	case a.amount < 0 && b.amount > 0:
+		from, to = a, b
+	case b.amount < 0 && a.amount > 0:
+		from, to = b, a
+	default:
+		return Transfer{}, false
+	}
+
+	return Transfer{
+		From:        from.transaction,
+		To:          to.transaction,
+		Amount:      a.amount.Abs(),
+		CardPayment: from.account.Type != TellerAccountTypeCredit && to.account.Type == TellerAccountTypeCredit,
+	}, true
+}
+
+// TransferTransactionIDs returns the IDs of the transactions that are legs of transfers
+func TransferTransactionIDs(transfers []Transfer) map[string]bool {
+	ids := make(map[string]bool, 2*len(transfers))
+	for _, transfer := range transfers {
+		ids[transfer.From.ID] = true
+		ids[transfer.To.ID] = true
+	}
+	return ids
+}
@@ -0,0 +1,148 @@
+package teller
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testTransaction returns a posted transaction of accountID
+func testTransaction(id, accountID, date, description, amount string) TellerTransaction {
+	return TellerTransaction{
+		ID:          id,
+		AccountID:   accountID,
+		Amount:      amount,
+		Date:        date,
+		Description: description,
+		Status:      TellerTransactionStatusTypePosted,
+	}
+}
+
+func TestMatchTransfers(t *testing.T) {
+	accounts := []TellerAccount{
+		{ID: "chk", Type: TellerAccountTypeDepository, Currency: "USD", LastFour: "1111"},
+		{ID: "sav", Type: TellerAccountTypeDepository, Currency: "USD", LastFour: "2222"},
+		{ID: "card", Type: TellerAccountTypeCredit, Currency: "USD", LastFour: "3333"},
+		{ID: "eur", Type: TellerAccountTypeDepository, Currency: "EUR", LastFour: "4444"},
+	}
+
+	tests := []struct {
+		name         string
+		transactions []TellerTransaction
+		want         []string
+		cardPayment  bool
+	}{
+		{
+			name: "hinted transfer to savings",
+			transactions: []TellerTransaction{
+				testTransaction("out", "chk", "2026-05-01", "ONLINE TRANSFER TO SAV", "-500.00"),
+				testTransaction("in", "sav", "2026-05-02", "ONLINE TRANSFER FROM CHK", "500.00"),
+			},
+			want: []string{"out>in"},
+		},
+		{
+			name: "last four digits",
+			transactions: []TellerTransaction{
+				testTransaction("out", "chk", "2026-05-01", "WEB XX2222", "-500.00"),
+				testTransaction("in", "sav", "2026-05-01", "DEPOSIT", "500.00"),
+			},
+			want: []string{"out>in"},
+		},
+		{
+			name: "paycheck and rent of the same amount",
+			transactions: []TellerTransaction{
+				testTransaction("rent", "chk", "2026-05-01", "OAK APARTMENTS", "-1500.00"),
+				testTransaction("pay", "sav", "2026-05-02", "ACME CORP", "1500.00"),
+			},
+		},
+		{
+			name: "card payment with a positive credit leg",
+			transactions: []TellerTransaction{
+				testTransaction("out", "chk", "2026-05-01", "CARD AUTOPAY", "-250.00"),
+				testTransaction("in", "card", "2026-05-02", "THANK YOU", "250.00"),
+			},
+			want:        []string{"out>in"},
+			cardPayment: true,
+		},
+		{
+			name: "unhinted card payment with a positive credit leg",
+			transactions: []TellerTransaction{
+				testTransaction("out", "chk", "2026-05-01", "CHASE", "-250.00"),
+				testTransaction("in", "card", "2026-05-02", "CHASE", "250.00"),
+			},
+			want:        []string{"out>in"},
+			cardPayment: true,
+		},
+		{
+			name: "purchases of the same amount on debit and credit",
+			transactions: []TellerTransaction{
+				testTransaction("debit", "chk", "2026-05-01", "COFFEE SHOP", "-4.50"),
+				testTransaction("credit", "card", "2026-05-01", "COFFEE SHOP", "-4.50"),
+			},
+		},
+		{
+			name: "different currencies",
+			transactions: []TellerTransaction{
+				testTransaction("out", "chk", "2026-05-01", "TRANSFER TO XX4444", "-100.00"),
+				testTransaction("in", "eur", "2026-05-01", "TRANSFER FROM XX1111", "100.00"),
+			},
+		},
+		{
+			name: "outside the window",
+			transactions: []TellerTransaction{
+				testTransaction("out", "chk", "2026-05-01", "TRANSFER", "-500.00"),
+				testTransaction("in", "sav", "2026-05-05", "TRANSFER", "500.00"),
+			},
+		},
+		{
+			name: "unknown account",
+			transactions: []TellerTransaction{
+				testTransaction("out", "chk", "2026-05-01", "TRANSFER", "-500.00"),
+				testTransaction("in", "other", "2026-05-01", "TRANSFER", "500.00"),
+			},
+		},
+		{
+			name: "hinted leg wins over a closer one",
+			transactions: []TellerTransaction{
+				testTransaction("out", "chk", "2026-05-01", "TRANSFER TO SAV", "-500.00"),
+				testTransaction("refund", "sav", "2026-05-01", "REFUND", "500.00"),
+				testTransaction("in", "sav", "2026-05-03", "TRANSFER FROM CHK", "500.00"),
+			},
+			want: []string{"out>in"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfers := MatchTransfers(accounts, tt.transactions, nil)
+
+			var got []string
+			for _, transfer := range transfers {
+				got = append(got, transfer.From.ID+">"+transfer.To.ID)
+				if transfer.CardPayment != tt.cardPayment {
+					t.Errorf("%s: CardPayment = %v, want %v", transfer.From.ID, transfer.CardPayment, tt.cardPayment)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("MatchTransfers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchTransfersSameEnrollment(t *testing.T) {
+	accounts := []TellerAccount{
+		{ID: "chk", EnrollmentID: "enr_1", Type: TellerAccountTypeDepository, Currency: "USD"},
+		{ID: "sav", EnrollmentID: "enr_2", Type: TellerAccountTypeDepository, Currency: "USD"},
+	}
+	transactions := []TellerTransaction{
+		testTransaction("out", "chk", "2026-05-01", "TRANSFER", "-500.00"),
+		testTransaction("in", "sav", "2026-05-01", "TRANSFER", "500.00"),
+	}
+
+	if got := MatchTransfers(accounts, transactions, nil); len(got) != 1 {
+		t.Errorf("MatchTransfers() = %d transfers, want 1", len(got))
+	}
+	if got := MatchTransfers(accounts, transactions, &TransferOptions{SameEnrollment: true}); len(got) != 0 {
+		t.Errorf("MatchTransfers(SameEnrollment) = %d transfers, want 0", len(got))
+	}
+}
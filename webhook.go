@@ -36,6 +36,17 @@ const (
 	EnrollmentDisconnectedReasonTypeUserActionWebLoginRequired           EnrollmentDisconnectedReasonType = "disconnected.user_action.web_login_required"
 )
 
+// Errors returned by ConstructWebhook when a webhook cannot be verified
+var (
+	ErrWebhookNoSigningSecrets   = errors.New("signing secrets are required")
+	ErrWebhookMissingSignature   = errors.New("missing Teller-Signature header")
+	ErrWebhookMissingTimestamp   = errors.New("missing signature timestamp")
+	ErrWebhookNoSignatures       = errors.New("no signatures found")
+	ErrWebhookInvalidTimestamp   = errors.New("invalid signature timestamp")
+	ErrWebhookTimestampTooOld    = errors.New("signature timestamp too old")
+	ErrWebhookVerificationFailed = errors.New("signature verification failed")
+)
+
 type WebhookVerificationStatus = string
 
 const (
@@ -68,7 +79,7 @@ type WebhookEvent struct {
 //   - signingSecrets: list of valid signing secrets to verify against.
 func ConstructWebhook(body []byte, signatureHeader string, signingSecrets []string) (*WebhookEvent, error) {
 	if len(signingSecrets) == 0 {
-		return nil, errors.New("signing secrets are required")
+		return nil, ErrWebhookNoSigningSecrets
 	}
 
 	tsValue, signatures, err := parseSignatureHeader(signatureHeader)
@@ -78,12 +89,12 @@ func ConstructWebhook(body []byte, signatureHeader string, signingSecrets []stri
 
 	tsUnix, err := strconv.ParseInt(tsValue, 10, 64)
 	if err != nil {
-		return nil, errors.New("invalid signature timestamp")
+		return nil, ErrWebhookInvalidTimestamp
 	}
 
 	timestamp := time.Unix(tsUnix, 0)
 	if time.Since(timestamp) > webhookTolerance {
-		return nil, errors.New("signature timestamp too old")
+		return nil, ErrWebhookTimestampTooOld
 	}
 
 	message := []byte(tsValue + "." + string(body))
@@ -111,7 +122,7 @@ func ConstructWebhook(body []byte, signatureHeader string, signingSecrets []stri
 	}
 
 	if !verified {
-		return nil, errors.New("signature verification failed")
+		return nil, ErrWebhookVerificationFailed
 	}
 
 	var event WebhookEvent
@@ -124,7 +135,7 @@ func ConstructWebhook(body []byte, signatureHeader string, signingSecrets []stri
 
 func parseSignatureHeader(header string) (string, []string, error) {
 	if header == "" {
-		return "", nil, errors.New("missing Teller-Signature header")
+		return "", nil, ErrWebhookMissingSignature
 	}
 
 	var ts string
@@ -149,10 +160,10 @@ func parseSignatureHeader(header string) (string, []string, error) {
 	}
 
 	if ts == "" {
-		return "", nil, errors.New("missing signature timestamp")
+		return "", nil, ErrWebhookMissingTimestamp
 	}
 	if len(signatures) == 0 {
-		return "", nil, errors.New("no signatures found")
+		return "", nil, ErrWebhookNoSignatures
 	}
 
 	return ts, signatures, nil